@@ -1,5 +1,8 @@
-package main
+// Package event defines the JSON document Claude Code sends to a status line
+// command on stdin.
+package event
 
+// StatusHookEvent is the status line hook input.
 type StatusHookEvent struct {
 	HookEventName  string    `json:"hook_event_name"`
 	SessionID      string    `json:"session_id"`
@@ -33,7 +36,3 @@ type Cost struct {
 	TotalLinesAdded    int     `json:"total_lines_added"`
 	TotalLinesRemoved  int     `json:"total_lines_removed"`
 }
-
-func GetModelMaxTokens(modelID string) int {
-	return 200000
-}
@@ -0,0 +1,163 @@
+// Package filecache persists small JSON values between status line
+// invocations. Every render is a separate process, so anything worth
+// remembering has to go through disk.
+package filecache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Cache is a directory of JSON values with the time each was stored. A nil
+// *Cache is valid and caches nothing.
+type Cache struct {
+	Dir string
+	Now func() time.Time
+}
+
+type cacheEntry struct {
+	StoredAt time.Time       `json:"stored_at"`
+	Value    json.RawMessage `json:"value"`
+}
+
+// dirName is the directory under the user cache directory.
+const dirName = "claudestatusline"
+
+const (
+	// MaxAge is how long an entry is kept after it was last stored. Keys
+	// name sessions and commits, so most entries are never looked up again
+	// once they are a few days old.
+	MaxAge = 7 * 24 * time.Hour
+
+	// tmpMaxAge is the age at which a temporary file is taken to belong to a
+	// process that exited before renaming it into place.
+	tmpMaxAge = time.Minute
+
+	// pruneInterval is how often Store looks for entries to remove, tracked
+	// by the modification time of pruneMarker.
+	pruneInterval = time.Hour
+	pruneMarker   = ".pruned"
+	tmpPattern    = ".tmp-*"
+)
+
+// New returns a cache in the user cache directory, or nil if there is no
+// usable cache directory.
+func New() *Cache {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil
+	}
+	return &Cache{
+		Dir: filepath.Join(dir, dirName),
+		Now: time.Now,
+	}
+}
+
+// Load decodes the value stored under key into v and reports how old it is.
+func (c *Cache) Load(key string, v any) (time.Duration, bool) {
+	if c == nil {
+		return 0, false
+	}
+
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return 0, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return 0, false
+	}
+	if err := json.Unmarshal(entry.Value, v); err != nil {
+		return 0, false
+	}
+	return c.now().Sub(entry.StoredAt), true
+}
+
+// Store saves v under key. The file is replaced atomically so concurrent
+// status line processes never observe a partial write.
+func (c *Cache) Store(key string, v any) error {
+	if c == nil {
+		return nil
+	}
+
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(cacheEntry{StoredAt: c.now(), Value: value})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(c.Dir, tmpPattern)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		return err
+	}
+
+	c.prune()
+	return nil
+}
+
+// prune removes entries not stored for MaxAge and temporary files left by
+// processes that exited mid-write, at most once per pruneInterval. Other
+// files in the directory are left alone.
+func (c *Cache) prune() {
+	now := c.now()
+	marker := filepath.Join(c.Dir, pruneMarker)
+	if info, err := os.Stat(marker); err == nil && now.Sub(info.ModTime()) < pruneInterval {
+		return
+	}
+	if err := os.WriteFile(marker, nil, 0600); err != nil {
+		return
+	}
+	_ = os.Chtimes(marker, now, now)
+
+	entries, err := os.ReadDir(c.Dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		maxAge := MaxAge
+		if ok, _ := filepath.Match(tmpPattern, entry.Name()); ok {
+			maxAge = tmpMaxAge
+		} else if filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		if info, err := entry.Info(); err == nil && now.Sub(info.ModTime()) > maxAge {
+			_ = os.Remove(filepath.Join(c.Dir, entry.Name()))
+		}
+	}
+}
+
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:16])+".json")
+}
+
+func (c *Cache) now() time.Time {
+	if c.Now == nil {
+		return time.Now()
+	}
+	return c.Now()
+}
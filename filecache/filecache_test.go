@@ -0,0 +1,86 @@
+package filecache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache := &Cache{Dir: t.TempDir(), Now: func() time.Time { return now }}
+
+	type value struct {
+		Count int `json:"count"`
+	}
+
+	var missing value
+	_, ok := cache.Load("key", &missing)
+	assert.False(t, ok)
+
+	require.NoError(t, cache.Store("key", value{Count: 3}))
+
+	now = now.Add(5 * time.Second)
+	var loaded value
+	age, ok := cache.Load("key", &loaded)
+	require.True(t, ok)
+	assert.Equal(t, 3, loaded.Count)
+	assert.Equal(t, 5*time.Second, age)
+
+	var other value
+	_, ok = cache.Load("other", &other)
+	assert.False(t, ok)
+}
+
+func TestNilCache(t *testing.T) {
+	var cache *Cache
+
+	assert.NoError(t, cache.Store("key", 1))
+
+	var v int
+	_, ok := cache.Load("key", &v)
+	assert.False(t, ok)
+}
+
+func TestCachePrune(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache := &Cache{Dir: t.TempDir(), Now: func() time.Time { return now }}
+
+	age := func(path string, age time.Duration) string {
+		t.Helper()
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			require.NoError(t, os.WriteFile(path, []byte("{}"), 0600))
+		}
+		require.NoError(t, os.Chtimes(path, now.Add(-age), now.Add(-age)))
+		return path
+	}
+
+	require.NoError(t, cache.Store("old", 1))
+	old := age(cache.path("old"), MaxAge+time.Hour)
+	recent := age(cache.path("recent"), MaxAge-time.Hour)
+	abandoned := age(filepath.Join(cache.Dir, ".tmp-123"), 2*time.Minute)
+	writing := age(filepath.Join(cache.Dir, ".tmp-456"), time.Second)
+	log := age(filepath.Join(cache.Dir, "debug.log"), 2*MaxAge)
+	require.NoError(t, os.Remove(filepath.Join(cache.Dir, pruneMarker)))
+
+	require.NoError(t, cache.Store("new", 2))
+	assert.NoFileExists(t, old)
+	assert.NoFileExists(t, abandoned)
+	assert.FileExists(t, recent)
+	assert.FileExists(t, writing)
+	assert.FileExists(t, log, "files the cache did not write are kept")
+	assert.FileExists(t, cache.path("new"))
+
+	old = age(cache.path("old"), MaxAge+time.Hour)
+	now = now.Add(pruneInterval / 2)
+	require.NoError(t, cache.Store("new", 3))
+	assert.FileExists(t, old, "pruning runs at most once per interval")
+
+	now = now.Add(pruneInterval)
+	require.NoError(t, cache.Store("new", 4))
+	assert.NoFileExists(t, old)
+}
@@ -0,0 +1,223 @@
+// Package git reads repository state for the status line, mostly straight
+// from the .git directory so that it stays fast in large repositories.
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bjulian5/claudestatusline/filecache"
+)
+
+// ErrNotRepository is returned by FindRepo when no directory above dir
+// contains a .git entry.
+var ErrNotRepository = errors.New("not a git repository")
+
+// Repo locates a repository's working tree and git directories. GitDir
+// holds per-worktree state such as HEAD and the index; CommonDir holds the
+// refs and config shared by all worktrees. They differ only in linked
+// worktrees, where WorktreeName is the name git gave the worktree.
+type Repo struct {
+	WorkTree     string
+	GitDir       string
+	CommonDir    string
+	WorktreeName string
+}
+
+// FindRepo walks up from dir until it finds a .git entry. A .git file, as
+// used by linked worktrees and submodules, is followed to the real git
+// directory.
+func FindRepo(dir string) (*Repo, error) {
+	for {
+		gitDir := filepath.Join(dir, ".git")
+		if info, err := os.Stat(gitDir); err == nil {
+			if !info.IsDir() {
+				return openGitFile(dir, gitDir)
+			}
+			return &Repo{WorkTree: dir, GitDir: gitDir, CommonDir: gitDir}, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir || parent == "/" {
+			return nil, ErrNotRepository
+		}
+		dir = parent
+	}
+}
+
+func openGitFile(workTree, gitFile string) (*Repo, error) {
+	content, err := os.ReadFile(gitFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read .git file: %w", err)
+	}
+
+	target, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir:")
+	if !ok {
+		return nil, fmt.Errorf("invalid .git file %s", gitFile)
+	}
+	gitDir := resolveGitPath(workTree, strings.TrimSpace(target))
+
+	repo := &Repo{WorkTree: workTree, GitDir: gitDir, CommonDir: gitDir}
+	if commonDir, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		repo.CommonDir = resolveGitPath(gitDir, strings.TrimSpace(string(commonDir)))
+		if filepath.Base(filepath.Dir(gitDir)) == "worktrees" {
+			repo.WorktreeName = filepath.Base(gitDir)
+		}
+	}
+	return repo, nil
+}
+
+func resolveGitPath(base, path string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(base, path)
+	}
+	return filepath.Clean(path)
+}
+
+// commonDir falls back to GitDir for repos built without a CommonDir.
+func (r *Repo) commonDir() string {
+	if r.CommonDir == "" {
+		return r.GitDir
+	}
+	return r.CommonDir
+}
+
+// Branch returns the checked out branch name, or an abbreviated commit hash
+// when HEAD is detached.
+func (r *Repo) Branch() (string, error) {
+	headFile := filepath.Join(r.GitDir, "HEAD")
+	content, err := os.ReadFile(headFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read HEAD file: %w", err)
+	}
+
+	ref := strings.TrimSpace(string(content))
+	if strings.HasPrefix(ref, "ref: refs/heads/") {
+		return strings.TrimPrefix(ref, "ref: refs/heads/"), nil
+	}
+
+	if len(ref) >= 7 {
+		return ref[:7] + "...", nil
+	}
+	return ref, nil
+}
+
+func GetBranch(dir string) (string, error) {
+	repo, err := FindRepo(dir)
+	if err != nil {
+		return "", err
+	}
+	return repo.Branch()
+}
+
+// Options controls the optional, more expensive parts of GetInfo.
+// StatusTimeout bounds each git command that has to be run.
+type Options struct {
+	Status        bool
+	AheadBehind   bool
+	StatusTimeout time.Duration
+	CacheTTL      time.Duration
+	Cache         *filecache.Cache
+}
+
+// Info is everything the git section displays.
+type Info struct {
+	Branch       string
+	WorktreeName string
+	Status       *Status
+	Ahead        int
+	Behind       int
+	Operation    *Operation
+}
+
+// GetInfo reads the branch for dir and, if requested, the working tree
+// status. A status that cannot be determined in time is left nil rather than
+// failing the whole section.
+func GetInfo(dir string, opts Options) (*Info, error) {
+	repo, err := FindRepo(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	branch, err := repo.Branch()
+	if err != nil {
+		return nil, err
+	}
+
+	info := &Info{Branch: branch, WorktreeName: repo.WorktreeName, Operation: repo.Operation()}
+	if opts.Status {
+		if status, err := repo.LoadStatus(opts.Cache, opts.StatusTimeout, opts.CacheTTL); err == nil {
+			info.Status = status
+		}
+	}
+	if opts.AheadBehind {
+		if ahead, behind, err := repo.AheadBehind(opts.Cache, opts.StatusTimeout); err == nil {
+			info.Ahead, info.Behind = ahead, behind
+		}
+	}
+	return info, nil
+}
+
+// Values returns the raw fields of the git section for structured output.
+func (g *Info) Values() map[string]any {
+	values := map[string]any{
+		"branch": g.Branch,
+		"ahead":  g.Ahead,
+		"behind": g.Behind,
+	}
+	if g.WorktreeName != "" {
+		values["worktree"] = g.WorktreeName
+	}
+	if g.Operation != nil {
+		values["operation"] = g.Operation.Name
+	}
+	if g.Status != nil {
+		values["staged"] = g.Status.Staged
+		values["modified"] = g.Status.Modified
+		values["untracked"] = g.Status.Untracked
+		values["conflicted"] = g.Status.Conflicted
+	}
+	return values
+}
+
+func (g *Info) String() string {
+	branch := g.Branch
+	if g.Operation != nil && g.Operation.Branch != "" {
+		// HEAD is detached mid-rebase; show the branch being rebased instead.
+		branch = g.Operation.Branch
+	}
+
+	parts := []string{branch}
+	if g.WorktreeName != "" {
+		parts = append(parts, "wt:"+g.WorktreeName)
+	}
+	if g.Operation != nil {
+		parts = append(parts, g.Operation.String())
+	}
+	if g.Ahead > 0 {
+		parts = append(parts, fmt.Sprintf("↑%d", g.Ahead))
+	}
+	if g.Behind > 0 {
+		parts = append(parts, fmt.Sprintf("↓%d", g.Behind))
+	}
+	if g.Status != nil {
+		for _, indicator := range []struct {
+			symbol string
+			count  int
+		}{
+			{"+", g.Status.Staged},
+			{"!", g.Status.Modified},
+			{"?", g.Status.Untracked},
+			{"=", g.Status.Conflicted},
+		} {
+			if indicator.count > 0 {
+				parts = append(parts, fmt.Sprintf("%s%d", indicator.symbol, indicator.count))
+			}
+		}
+	}
+	return strings.Join(parts, " ")
+}
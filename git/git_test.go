@@ -0,0 +1,242 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBranch(t *testing.T) {
+	t.Run("branch ref in HEAD", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		gitDir := filepath.Join(tmpDir, ".git")
+		require.NoError(t, os.Mkdir(gitDir, 0755))
+
+		headFile := filepath.Join(gitDir, "HEAD")
+		err := os.WriteFile(headFile, []byte("ref: refs/heads/main\n"), 0644)
+		require.NoError(t, err)
+
+		branch, err := GetBranch(tmpDir)
+		assert.NoError(t, err)
+		assert.Equal(t, "main", branch)
+	})
+
+	t.Run("detached HEAD with long commit hash", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		gitDir := filepath.Join(tmpDir, ".git")
+		require.NoError(t, os.Mkdir(gitDir, 0755))
+
+		headFile := filepath.Join(gitDir, "HEAD")
+		err := os.WriteFile(headFile, []byte("abc123456789def\n"), 0644)
+		require.NoError(t, err)
+
+		branch, err := GetBranch(tmpDir)
+		assert.NoError(t, err)
+		assert.Equal(t, "abc1234...", branch)
+	})
+
+	t.Run("detached HEAD with short commit hash", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		gitDir := filepath.Join(tmpDir, ".git")
+		require.NoError(t, os.Mkdir(gitDir, 0755))
+
+		headFile := filepath.Join(gitDir, "HEAD")
+		err := os.WriteFile(headFile, []byte("abc123\n"), 0644)
+		require.NoError(t, err)
+
+		branch, err := GetBranch(tmpDir)
+		assert.NoError(t, err)
+		assert.Equal(t, "abc123", branch)
+	})
+
+	t.Run("finds git directory in parent", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		gitDir := filepath.Join(tmpDir, ".git")
+		require.NoError(t, os.Mkdir(gitDir, 0755))
+
+		headFile := filepath.Join(gitDir, "HEAD")
+		err := os.WriteFile(headFile, []byte("ref: refs/heads/develop\n"), 0644)
+		require.NoError(t, err)
+
+		subDir := filepath.Join(tmpDir, "subdir", "deep")
+		require.NoError(t, os.MkdirAll(subDir, 0755))
+
+		branch, err := GetBranch(subDir)
+		assert.NoError(t, err)
+		assert.Equal(t, "develop", branch)
+	})
+
+	t.Run("not a git repository", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		branch, err := GetBranch(tmpDir)
+		assert.ErrorIs(t, err, ErrNotRepository)
+		assert.Empty(t, branch)
+	})
+
+	t.Run("HEAD file unreadable", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		gitDir := filepath.Join(tmpDir, ".git")
+		require.NoError(t, os.Mkdir(gitDir, 0755))
+
+		headFile := filepath.Join(gitDir, "HEAD")
+		require.NoError(t, os.WriteFile(headFile, []byte("ref: refs/heads/main\n"), 0000))
+
+		branch, err := GetBranch(tmpDir)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to read HEAD file")
+		assert.Empty(t, branch)
+	})
+}
+
+func TestFindRepo(t *testing.T) {
+	t.Run("git directory", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		gitDir := filepath.Join(tmpDir, ".git")
+		require.NoError(t, os.Mkdir(gitDir, 0755))
+
+		repo, err := FindRepo(tmpDir)
+		require.NoError(t, err)
+		assert.Equal(t, &Repo{WorkTree: tmpDir, GitDir: gitDir, CommonDir: gitDir}, repo)
+	})
+
+	t.Run("submodule gitdir file", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		moduleGitDir := filepath.Join(tmpDir, ".git", "modules", "lib")
+		require.NoError(t, os.MkdirAll(moduleGitDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(moduleGitDir, "HEAD"), []byte("ref: refs/heads/lib-main\n"), 0644))
+
+		moduleDir := filepath.Join(tmpDir, "lib")
+		require.NoError(t, os.Mkdir(moduleDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(moduleDir, ".git"), []byte("gitdir: ../.git/modules/lib\n"), 0644))
+
+		repo, err := FindRepo(moduleDir)
+		require.NoError(t, err)
+		assert.Equal(t, moduleGitDir, repo.GitDir)
+		assert.Equal(t, moduleGitDir, repo.CommonDir)
+		assert.Empty(t, repo.WorktreeName)
+
+		branch, err := GetBranch(moduleDir)
+		require.NoError(t, err)
+		assert.Equal(t, "lib-main", branch)
+	})
+
+	t.Run("linked worktree", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		commonDir := filepath.Join(tmpDir, "main", ".git")
+		worktreeGitDir := filepath.Join(commonDir, "worktrees", "feature")
+		require.NoError(t, os.MkdirAll(worktreeGitDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(worktreeGitDir, "commondir"), []byte("../..\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(worktreeGitDir, "HEAD"), []byte("ref: refs/heads/feature\n"), 0644))
+
+		worktreeDir := filepath.Join(tmpDir, "feature")
+		require.NoError(t, os.Mkdir(worktreeDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(worktreeDir, ".git"), []byte("gitdir: "+worktreeGitDir+"\n"), 0644))
+
+		repo, err := FindRepo(worktreeDir)
+		require.NoError(t, err)
+		assert.Equal(t, worktreeDir, repo.WorkTree)
+		assert.Equal(t, worktreeGitDir, repo.GitDir)
+		assert.Equal(t, commonDir, repo.CommonDir)
+		assert.Equal(t, "feature", repo.WorktreeName)
+
+		info, err := GetInfo(worktreeDir, Options{})
+		require.NoError(t, err)
+		assert.Equal(t, "feature wt:feature", info.String())
+	})
+
+	t.Run("invalid gitdir file", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".git"), []byte("nonsense\n"), 0644))
+
+		_, err := FindRepo(tmpDir)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid .git file")
+	})
+
+	t.Run("worktree created by git", func(t *testing.T) {
+		dir := initGitRepo(t)
+		runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "initial")
+		worktreeDir := filepath.Join(t.TempDir(), "review")
+		runGit(t, dir, "worktree", "add", "-q", "-b", "review", worktreeDir)
+		runGit(t, worktreeDir, "branch", "--set-upstream-to=main")
+		runGit(t, worktreeDir, "commit", "-q", "--allow-empty", "-m", "review change")
+
+		info, err := GetInfo(worktreeDir, Options{Status: true, AheadBehind: true, StatusTimeout: 5 * time.Second})
+		require.NoError(t, err)
+		assert.Equal(t, "review", info.Branch)
+		assert.Equal(t, "review", info.WorktreeName)
+		assert.Equal(t, 1, info.Ahead)
+		require.NotNil(t, info.Status)
+		assert.True(t, info.Status.IsClean())
+	})
+}
+
+func TestInfoString(t *testing.T) {
+	tests := []struct {
+		name     string
+		info     Info
+		expected string
+	}{
+		{
+			name:     "branch only",
+			info:     Info{Branch: "main"},
+			expected: "main",
+		},
+		{
+			name:     "clean status",
+			info:     Info{Branch: "main", Status: &Status{}},
+			expected: "main",
+		},
+		{
+			name:     "dirty status",
+			info:     Info{Branch: "main", Status: &Status{Staged: 1, Modified: 2, Untracked: 3, Conflicted: 4}},
+			expected: "main +1 !2 ?3 =4",
+		},
+		{
+			name:     "linked worktree",
+			info:     Info{Branch: "main", WorktreeName: "review"},
+			expected: "main wt:review",
+		},
+		{
+			name:     "rebase in progress",
+			info:     Info{Branch: "abc1234...", Operation: &Operation{Name: "REBASE", Step: 3, Total: 7, Branch: "feature"}},
+			expected: "feature REBASE 3/7",
+		},
+		{
+			name:     "merge in progress",
+			info:     Info{Branch: "main", Operation: &Operation{Name: "MERGE"}, Status: &Status{Conflicted: 2}},
+			expected: "main MERGE =2",
+		},
+		{
+			name:     "ahead and behind",
+			info:     Info{Branch: "main", Ahead: 2, Behind: 1, Status: &Status{Modified: 1}},
+			expected: "main ↑2 ↓1 !1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.info.String())
+		})
+	}
+}
+
+func TestGetInfo(t *testing.T) {
+	tmpDir := t.TempDir()
+	gitDir := filepath.Join(tmpDir, ".git")
+	require.NoError(t, os.Mkdir(gitDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(gitDir, "HEAD"), []byte("ref: refs/heads/main\n"), 0644))
+
+	info, err := GetInfo(tmpDir, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "main", info.Branch)
+	assert.Nil(t, info.Status)
+
+	_, err = GetInfo(t.TempDir(), Options{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,100 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Operation describes a multi-step git command that is in progress, such
+// as a rebase stopped on a conflict.
+type Operation struct {
+	Name   string
+	Step   int
+	Total  int
+	Branch string
+}
+
+func (o *Operation) String() string {
+	if o.Total > 0 {
+		return fmt.Sprintf("%s %d/%d", o.Name, o.Step, o.Total)
+	}
+	return o.Name
+}
+
+// Operation returns the git operation in progress in this worktree, or nil.
+func (r *Repo) Operation() *Operation {
+	if dir := filepath.Join(r.GitDir, "rebase-merge"); isDir(dir) {
+		return &Operation{
+			Name:   "REBASE",
+			Step:   readIntFile(filepath.Join(dir, "msgnum")),
+			Total:  readIntFile(filepath.Join(dir, "end")),
+			Branch: readHeadName(filepath.Join(dir, "head-name")),
+		}
+	}
+
+	if dir := filepath.Join(r.GitDir, "rebase-apply"); isDir(dir) {
+		name := "AM/REBASE"
+		if fileExists(filepath.Join(dir, "rebasing")) {
+			name = "REBASE"
+		} else if fileExists(filepath.Join(dir, "applying")) {
+			name = "AM"
+		}
+		return &Operation{
+			Name:   name,
+			Step:   readIntFile(filepath.Join(dir, "next")),
+			Total:  readIntFile(filepath.Join(dir, "last")),
+			Branch: readHeadName(filepath.Join(dir, "head-name")),
+		}
+	}
+
+	for _, marker := range []struct {
+		file string
+		name string
+	}{
+		{"MERGE_HEAD", "MERGE"},
+		{"CHERRY_PICK_HEAD", "CHERRY-PICK"},
+		{"REVERT_HEAD", "REVERT"},
+		{"BISECT_LOG", "BISECT"},
+	} {
+		if fileExists(filepath.Join(r.GitDir, marker.file)) {
+			return &Operation{Name: marker.name}
+		}
+	}
+	return nil
+}
+
+func readIntFile(path string) int {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(string(content)))
+	return n
+}
+
+// readHeadName returns the short branch name recorded in a rebase head-name
+// file. Rebases of a detached HEAD record "detached HEAD", which is ignored.
+func readHeadName(path string) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	branch, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "refs/heads/")
+	if !ok {
+		return ""
+	}
+	return branch
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
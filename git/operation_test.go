@@ -0,0 +1,125 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepoOperation(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected *Operation
+	}{
+		{
+			name:     "no operation",
+			files:    map[string]string{},
+			expected: nil,
+		},
+		{
+			name: "interactive rebase",
+			files: map[string]string{
+				"rebase-merge/msgnum":    "3\n",
+				"rebase-merge/end":       "7\n",
+				"rebase-merge/head-name": "refs/heads/feature\n",
+			},
+			expected: &Operation{Name: "REBASE", Step: 3, Total: 7, Branch: "feature"},
+		},
+		{
+			name: "apply rebase",
+			files: map[string]string{
+				"rebase-apply/next":      "1\n",
+				"rebase-apply/last":      "2\n",
+				"rebase-apply/rebasing":  "",
+				"rebase-apply/head-name": "refs/heads/topic\n",
+			},
+			expected: &Operation{Name: "REBASE", Step: 1, Total: 2, Branch: "topic"},
+		},
+		{
+			name: "git am",
+			files: map[string]string{
+				"rebase-apply/next":     "2\n",
+				"rebase-apply/last":     "4\n",
+				"rebase-apply/applying": "",
+			},
+			expected: &Operation{Name: "AM", Step: 2, Total: 4},
+		},
+		{
+			name: "rebase of detached HEAD",
+			files: map[string]string{
+				"rebase-merge/msgnum":    "1\n",
+				"rebase-merge/end":       "1\n",
+				"rebase-merge/head-name": "detached HEAD\n",
+			},
+			expected: &Operation{Name: "REBASE", Step: 1, Total: 1},
+		},
+		{
+			name:     "merge",
+			files:    map[string]string{"MERGE_HEAD": testCommitA + "\n"},
+			expected: &Operation{Name: "MERGE"},
+		},
+		{
+			name:     "cherry-pick",
+			files:    map[string]string{"CHERRY_PICK_HEAD": testCommitA + "\n"},
+			expected: &Operation{Name: "CHERRY-PICK"},
+		},
+		{
+			name:     "revert",
+			files:    map[string]string{"REVERT_HEAD": testCommitA + "\n"},
+			expected: &Operation{Name: "REVERT"},
+		},
+		{
+			name:     "bisect",
+			files:    map[string]string{"BISECT_LOG": "git bisect start\n"},
+			expected: &Operation{Name: "BISECT"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeGitRepo(t)
+			for name, content := range tt.files {
+				writeGitFile(t, repo.GitDir, name, content)
+			}
+			assert.Equal(t, tt.expected, repo.Operation())
+		})
+	}
+}
+
+func TestOperationString(t *testing.T) {
+	assert.Equal(t, "REBASE 3/7", (&Operation{Name: "REBASE", Step: 3, Total: 7}).String())
+	assert.Equal(t, "MERGE", (&Operation{Name: "MERGE"}).String())
+}
+
+func TestInfoDuringRebase(t *testing.T) {
+	dir := initGitRepo(t)
+	path := filepath.Join(dir, "file.txt")
+	require.NoError(t, os.WriteFile(path, []byte("base\n"), 0644))
+	runGit(t, dir, "add", "file.txt")
+	runGit(t, dir, "commit", "-q", "-m", "base")
+
+	runGit(t, dir, "checkout", "-q", "-b", "feature")
+	for _, content := range []string{"feature 1\n", "feature 2\n"} {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		runGit(t, dir, "commit", "-q", "-am", content)
+	}
+
+	runGit(t, dir, "checkout", "-q", "main")
+	require.NoError(t, os.WriteFile(path, []byte("main\n"), 0644))
+	runGit(t, dir, "commit", "-q", "-am", "main")
+	runGit(t, dir, "checkout", "-q", "feature")
+
+	cmd := exec.Command("git", "rebase", "--merge", "main")
+	cmd.Dir = dir
+	require.Error(t, cmd.Run(), "rebase should stop on a conflict")
+
+	info, err := GetInfo(dir, Options{})
+	require.NoError(t, err)
+	require.NotNil(t, info.Operation)
+	assert.Equal(t, "feature REBASE 1/2", info.String())
+}
@@ -0,0 +1,124 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/bjulian5/claudestatusline/filecache"
+)
+
+const (
+	DefaultStatusTimeout  = 300 * time.Millisecond
+	DefaultStatusCacheTTL = 5 * time.Second
+)
+
+// Status counts working tree changes by kind.
+type Status struct {
+	Staged     int `json:"staged"`
+	Modified   int `json:"modified"`
+	Untracked  int `json:"untracked"`
+	Conflicted int `json:"conflicted"`
+}
+
+func (s Status) IsClean() bool {
+	return s == Status{}
+}
+
+type cachedStatus struct {
+	Fingerprint string `json:"fingerprint"`
+	Status      Status `json:"status"`
+}
+
+// LoadStatus returns the working tree status, reusing a cached result while
+// HEAD and the index are unchanged and the result is younger than ttl. git is
+// only run when the cache is stale, and is killed after timeout; in that case
+// the last known status is returned if there is one.
+func (r *Repo) LoadStatus(cache *filecache.Cache, timeout, ttl time.Duration) (*Status, error) {
+	key := "git-status:" + r.WorkTree
+	fingerprint := r.statusFingerprint()
+
+	var cached cachedStatus
+	age, found := cache.Load(key, &cached)
+	if found && cached.Fingerprint == fingerprint && age < ttl {
+		return &cached.Status, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	status, err := r.Status(ctx)
+	if err != nil {
+		if found {
+			return &cached.Status, nil
+		}
+		return nil, err
+	}
+
+	_ = cache.Store(key, cachedStatus{Fingerprint: fingerprint, Status: *status})
+	return status, nil
+}
+
+// Status runs git status and counts the changed files.
+func (r *Repo) Status(ctx context.Context) (*Status, error) {
+	cmd := exec.CommandContext(ctx, "git", "--no-optional-locks", "status",
+		"--porcelain=v2", "-z", "--untracked-files=normal")
+	cmd.Dir = r.WorkTree
+
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("git status timed out: %w", ctx.Err())
+		}
+		return nil, fmt.Errorf("git status failed: %w", err)
+	}
+	return parsePorcelainStatus(output), nil
+}
+
+// statusFingerprint changes whenever HEAD moves or the index is rewritten.
+func (r *Repo) statusFingerprint() string {
+	head, _ := os.ReadFile(filepath.Join(r.GitDir, "HEAD"))
+	fingerprint := string(bytes.TrimSpace(head))
+	if info, err := os.Stat(filepath.Join(r.GitDir, "index")); err == nil {
+		fingerprint += fmt.Sprintf(":%d:%d", info.ModTime().UnixNano(), info.Size())
+	}
+	return fingerprint
+}
+
+// parsePorcelainStatus parses the NUL separated output of
+// `git status --porcelain=v2 -z`.
+func parsePorcelainStatus(output []byte) *Status {
+	status := &Status{}
+	records := bytes.Split(output, []byte{0})
+	for i := 0; i < len(records); i++ {
+		record := records[i]
+		if len(record) == 0 {
+			continue
+		}
+
+		switch record[0] {
+		case '1', '2':
+			if len(record) >= 4 {
+				if record[2] != '.' {
+					status.Staged++
+				}
+				if record[3] != '.' {
+					status.Modified++
+				}
+			}
+			if record[0] == '2' {
+				// Renames and copies are followed by the original path.
+				i++
+			}
+		case 'u':
+			status.Conflicted++
+		case '?':
+			status.Untracked++
+		}
+	}
+	return status
+}
@@ -0,0 +1,119 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/claudestatusline/filecache"
+)
+
+func TestParsePorcelainStatus(t *testing.T) {
+	output := "1 M. N... 100644 100644 100644 abc abc staged.go\x00" +
+		"1 .M N... 100644 100644 100644 abc abc modified.go\x00" +
+		"1 MM N... 100644 100644 100644 abc abc both.go\x00" +
+		"2 R. N... 100644 100644 100644 abc abc R100 new.go\x00old.go\x00" +
+		"u UU N... 100644 100644 100644 100644 abc abc abc conflict.go\x00" +
+		"? untracked.go\x00" +
+		"? other.txt\x00" +
+		"! ignored.log\x00"
+
+	status := parsePorcelainStatus([]byte(output))
+	assert.Equal(t, Status{Staged: 3, Modified: 2, Untracked: 2, Conflicted: 1}, *status)
+	assert.True(t, parsePorcelainStatus(nil).IsClean())
+}
+
+func initGitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q", "-b", "main")
+	runGit(t, dir, "config", "user.email", "test@example.com")
+	runGit(t, dir, "config", "user.name", "Test")
+	return dir
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %v: %s", args, output)
+}
+
+func TestRepoStatus(t *testing.T) {
+	dir := initGitRepo(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tracked.txt"), []byte("one\n"), 0644))
+	runGit(t, dir, "add", "tracked.txt")
+	runGit(t, dir, "commit", "-q", "-m", "initial")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tracked.txt"), []byte("two\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "staged.txt"), []byte("new\n"), 0644))
+	runGit(t, dir, "add", "staged.txt")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "untracked.txt"), []byte("?\n"), 0644))
+
+	repo, err := FindRepo(dir)
+	require.NoError(t, err)
+
+	status, err := repo.Status(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Status{Staged: 1, Modified: 1, Untracked: 1}, *status)
+}
+
+func TestRepoLoadStatus(t *testing.T) {
+	t.Run("uses cached status while fingerprint matches", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		gitDir := filepath.Join(tmpDir, ".git")
+		require.NoError(t, os.Mkdir(gitDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(gitDir, "HEAD"), []byte("ref: refs/heads/main\n"), 0644))
+
+		repo := &Repo{WorkTree: tmpDir, GitDir: gitDir}
+		cache := &filecache.Cache{Dir: t.TempDir()}
+		cached := Status{Modified: 4}
+		require.NoError(t, cache.Store("git-status:"+tmpDir, cachedStatus{
+			Fingerprint: repo.statusFingerprint(),
+			Status:      cached,
+		}))
+
+		status, err := repo.LoadStatus(cache, time.Second, time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, cached, *status)
+	})
+
+	t.Run("refreshes when the index changes", func(t *testing.T) {
+		dir := initGitRepo(t)
+		repo, err := FindRepo(dir)
+		require.NoError(t, err)
+
+		cache := &filecache.Cache{Dir: t.TempDir()}
+		status, err := repo.LoadStatus(cache, 5*time.Second, time.Minute)
+		require.NoError(t, err)
+		assert.True(t, status.IsClean())
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "file.txt"), []byte("x\n"), 0644))
+		runGit(t, dir, "add", "file.txt")
+
+		status, err = repo.LoadStatus(cache, 5*time.Second, time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, Status{Staged: 1}, *status)
+	})
+
+	t.Run("git failure without cache returns error", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		gitDir := filepath.Join(tmpDir, ".git")
+		require.NoError(t, os.Mkdir(gitDir, 0755))
+
+		repo := &Repo{WorkTree: tmpDir, GitDir: gitDir}
+		_, err := repo.LoadStatus(nil, time.Second, time.Minute)
+		assert.Error(t, err)
+	})
+}
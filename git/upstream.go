@@ -0,0 +1,211 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bjulian5/claudestatusline/filecache"
+)
+
+const maxSymbolicRefDepth = 5
+
+// HeadRef returns the full ref HEAD points at, e.g. "refs/heads/main", or an
+// empty string when HEAD is detached.
+func (r *Repo) HeadRef() (string, error) {
+	content, err := os.ReadFile(filepath.Join(r.GitDir, "HEAD"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read HEAD file: %w", err)
+	}
+
+	ref := strings.TrimSpace(string(content))
+	if target, ok := strings.CutPrefix(ref, "ref: "); ok {
+		return target, nil
+	}
+	return "", nil
+}
+
+// ResolveRef returns the commit hash for a full ref name, checking loose refs
+// before packed-refs and following symbolic refs.
+func (r *Repo) ResolveRef(ref string) (string, error) {
+	for range maxSymbolicRefDepth {
+		content, err := os.ReadFile(filepath.Join(r.refDir(ref), filepath.FromSlash(ref)))
+		if err != nil {
+			return r.resolvePackedRef(ref)
+		}
+
+		value := strings.TrimSpace(string(content))
+		target, symbolic := strings.CutPrefix(value, "ref: ")
+		if !symbolic {
+			return value, nil
+		}
+		ref = target
+	}
+	return "", fmt.Errorf("too many levels of symbolic refs resolving %s", ref)
+}
+
+// refDir returns the directory a loose ref lives in. HEAD-like pseudo refs
+// belong to the worktree; everything under refs/ is shared.
+func (r *Repo) refDir(ref string) string {
+	if strings.HasPrefix(ref, "refs/") {
+		return r.commonDir()
+	}
+	return r.GitDir
+}
+
+func (r *Repo) resolvePackedRef(ref string) (string, error) {
+	file, err := os.Open(filepath.Join(r.commonDir(), "packed-refs"))
+	if err != nil {
+		return "", fmt.Errorf("ref %s not found", ref)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || line[0] == '#' || line[0] == '^' {
+			continue
+		}
+
+		hash, name, ok := strings.Cut(line, " ")
+		if ok && name == ref {
+			return hash, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read packed-refs: %w", err)
+	}
+	return "", fmt.Errorf("ref %s not found", ref)
+}
+
+// Upstream returns the ref that branch (a short name such as "main") tracks
+// according to the repository config.
+func (r *Repo) Upstream(branch string) (string, error) {
+	config, err := readGitConfig(filepath.Join(r.commonDir(), "config"))
+	if err != nil {
+		return "", err
+	}
+
+	section := fmt.Sprintf("branch %q", branch)
+	remote := config[section+".remote"]
+	merge := config[section+".merge"]
+	if remote == "" || merge == "" {
+		return "", fmt.Errorf("branch %s has no upstream", branch)
+	}
+
+	if remote == "." {
+		return merge, nil
+	}
+	return "refs/remotes/" + remote + "/" + strings.TrimPrefix(merge, "refs/heads/"), nil
+}
+
+// readGitConfig reads the subset of the git config format needed to find
+// upstreams. Keys are returned as `section "subsection".key` with the
+// section and key lowercased, as git treats them case-insensitively.
+func readGitConfig(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read git config: %w", err)
+	}
+	defer file.Close()
+
+	values := make(map[string]string)
+	section := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		if line[0] == '[' {
+			header := strings.TrimSuffix(strings.TrimPrefix(line, "["), "]")
+			name, subsection, hasSubsection := strings.Cut(header, " ")
+			section = strings.ToLower(name)
+			if hasSubsection {
+				section += " " + strings.TrimSpace(subsection)
+			}
+			continue
+		}
+
+		key, value, _ := strings.Cut(line, "=")
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		values[section+"."+strings.ToLower(strings.TrimSpace(key))] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read git config: %w", err)
+	}
+	return values, nil
+}
+
+type aheadBehind struct {
+	Ahead  int `json:"ahead"`
+	Behind int `json:"behind"`
+}
+
+// AheadBehind counts the commits on the current branch that are not on its
+// upstream and vice versa. Identical refs are answered without running git,
+// and because commit history is immutable the counts for a given pair of
+// commits are cached until the cache prunes them.
+func (r *Repo) AheadBehind(cache *filecache.Cache, timeout time.Duration) (ahead, behind int, err error) {
+	headRef, err := r.HeadRef()
+	if err != nil {
+		return 0, 0, err
+	}
+	branch, ok := strings.CutPrefix(headRef, "refs/heads/")
+	if !ok {
+		return 0, 0, fmt.Errorf("HEAD is not on a branch")
+	}
+
+	upstreamRef, err := r.Upstream(branch)
+	if err != nil {
+		return 0, 0, err
+	}
+	local, err := r.ResolveRef(headRef)
+	if err != nil {
+		return 0, 0, err
+	}
+	upstream, err := r.ResolveRef(upstreamRef)
+	if err != nil {
+		return 0, 0, err
+	}
+	if local == upstream {
+		return 0, 0, nil
+	}
+
+	key := "git-ahead-behind:" + local + "..." + upstream
+	var cached aheadBehind
+	if _, found := cache.Load(key, &cached); found {
+		return cached.Ahead, cached.Behind, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", "rev-list", "--left-right", "--count", local+"..."+upstream)
+	cmd.Dir = r.WorkTree
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, 0, fmt.Errorf("git rev-list failed: %w", err)
+	}
+
+	fields := strings.Fields(string(output))
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected git rev-list output %q", output)
+	}
+	if ahead, err = strconv.Atoi(fields[0]); err != nil {
+		return 0, 0, fmt.Errorf("unexpected git rev-list output %q", output)
+	}
+	if behind, err = strconv.Atoi(fields[1]); err != nil {
+		return 0, 0, fmt.Errorf("unexpected git rev-list output %q", output)
+	}
+
+	_ = cache.Store(key, aheadBehind{Ahead: ahead, Behind: behind})
+	return ahead, behind, nil
+}
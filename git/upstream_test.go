@@ -0,0 +1,173 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/claudestatusline/filecache"
+)
+
+const (
+	testCommitA = "1111111111111111111111111111111111111111"
+	testCommitB = "2222222222222222222222222222222222222222"
+)
+
+func writeGitFile(t *testing.T, gitDir, name, content string) {
+	t.Helper()
+	path := filepath.Join(gitDir, filepath.FromSlash(name))
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func newFakeGitRepo(t *testing.T) *Repo {
+	t.Helper()
+	tmpDir := t.TempDir()
+	gitDir := filepath.Join(tmpDir, ".git")
+	require.NoError(t, os.Mkdir(gitDir, 0755))
+	return &Repo{WorkTree: tmpDir, GitDir: gitDir}
+}
+
+func TestRepoResolveRef(t *testing.T) {
+	repo := newFakeGitRepo(t)
+	writeGitFile(t, repo.GitDir, "refs/heads/main", testCommitA+"\n")
+	writeGitFile(t, repo.GitDir, "refs/remotes/origin/HEAD", "ref: refs/remotes/origin/main\n")
+	writeGitFile(t, repo.GitDir, "packed-refs", "# pack-refs with: peeled fully-peeled sorted\n"+
+		testCommitB+" refs/remotes/origin/main\n"+
+		"^"+testCommitA+"\n"+
+		testCommitA+" refs/tags/v1\n")
+
+	tests := []struct {
+		ref      string
+		expected string
+		wantErr  bool
+	}{
+		{ref: "refs/heads/main", expected: testCommitA},
+		{ref: "refs/remotes/origin/main", expected: testCommitB},
+		{ref: "refs/remotes/origin/HEAD", expected: testCommitB},
+		{ref: "refs/tags/v1", expected: testCommitA},
+		{ref: "refs/heads/missing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			hash, err := repo.ResolveRef(tt.ref)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, hash)
+		})
+	}
+}
+
+func TestRepoUpstream(t *testing.T) {
+	repo := newFakeGitRepo(t)
+	writeGitFile(t, repo.GitDir, "config", `[core]
+	bare = false
+# a comment
+[remote "origin"]
+	url = git@example.com:repo.git
+[branch "main"]
+	remote = origin
+	merge = refs/heads/main
+[Branch "feature/x"]
+	Remote = upstream
+	Merge = "refs/heads/develop"
+[branch "local"]
+	remote = .
+	merge = refs/heads/main
+[branch "orphan"]
+	rebase = true
+`)
+
+	tests := []struct {
+		branch   string
+		expected string
+		wantErr  bool
+	}{
+		{branch: "main", expected: "refs/remotes/origin/main"},
+		{branch: "feature/x", expected: "refs/remotes/upstream/develop"},
+		{branch: "local", expected: "refs/heads/main"},
+		{branch: "orphan", wantErr: true},
+		{branch: "missing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			upstream, err := repo.Upstream(tt.branch)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, upstream)
+		})
+	}
+}
+
+func TestRepoAheadBehind(t *testing.T) {
+	t.Run("identical refs do not run git", func(t *testing.T) {
+		repo := newFakeGitRepo(t)
+		writeGitFile(t, repo.GitDir, "HEAD", "ref: refs/heads/main\n")
+		writeGitFile(t, repo.GitDir, "config", "[branch \"main\"]\n\tremote = origin\n\tmerge = refs/heads/main\n")
+		writeGitFile(t, repo.GitDir, "refs/heads/main", testCommitA+"\n")
+		writeGitFile(t, repo.GitDir, "packed-refs", testCommitA+" refs/remotes/origin/main\n")
+
+		ahead, behind, err := repo.AheadBehind(nil, time.Second)
+		require.NoError(t, err)
+		assert.Zero(t, ahead)
+		assert.Zero(t, behind)
+	})
+
+	t.Run("cached counts are reused", func(t *testing.T) {
+		repo := newFakeGitRepo(t)
+		writeGitFile(t, repo.GitDir, "HEAD", "ref: refs/heads/main\n")
+		writeGitFile(t, repo.GitDir, "config", "[branch \"main\"]\n\tremote = origin\n\tmerge = refs/heads/main\n")
+		writeGitFile(t, repo.GitDir, "refs/heads/main", testCommitA+"\n")
+		writeGitFile(t, repo.GitDir, "refs/remotes/origin/main", testCommitB+"\n")
+
+		cache := &filecache.Cache{Dir: t.TempDir()}
+		require.NoError(t, cache.Store("git-ahead-behind:"+testCommitA+"..."+testCommitB, aheadBehind{Ahead: 2, Behind: 5}))
+
+		ahead, behind, err := repo.AheadBehind(cache, time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, 2, ahead)
+		assert.Equal(t, 5, behind)
+	})
+
+	t.Run("detached HEAD", func(t *testing.T) {
+		repo := newFakeGitRepo(t)
+		writeGitFile(t, repo.GitDir, "HEAD", testCommitA+"\n")
+
+		_, _, err := repo.AheadBehind(nil, time.Second)
+		assert.Error(t, err)
+	})
+
+	t.Run("counts diverged history with git", func(t *testing.T) {
+		dir := initGitRepo(t)
+		runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "base")
+		runGit(t, dir, "branch", "upstream")
+		runGit(t, dir, "branch", "--set-upstream-to=upstream")
+		runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "local 1")
+		runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "local 2")
+		runGit(t, dir, "checkout", "-q", "upstream")
+		runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "remote 1")
+		runGit(t, dir, "checkout", "-q", "main")
+		runGit(t, dir, "pack-refs", "--all")
+
+		repo, err := FindRepo(dir)
+		require.NoError(t, err)
+
+		cache := &filecache.Cache{Dir: t.TempDir()}
+		ahead, behind, err := repo.AheadBehind(cache, 5*time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, 2, ahead)
+		assert.Equal(t, 1, behind)
+	})
+}
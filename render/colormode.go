@@ -0,0 +1,229 @@
+package render
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// ColorMode is how many colors the output may use.
+type ColorMode int
+
+const (
+	ColorNone ColorMode = iota
+	Color16
+	Color256
+	ColorTrueColor
+)
+
+// Values of the config "color" setting.
+const (
+	ColorSettingAuto      = "auto"
+	ColorSettingAlways    = "always"
+	ColorSettingNever     = "never"
+	ColorSetting16        = "16"
+	ColorSetting256       = "256"
+	ColorSettingTrueColor = "truecolor"
+)
+
+var colorSettings = map[string]ColorMode{
+	ColorSettingNever:     ColorNone,
+	ColorSetting16:        Color16,
+	ColorSetting256:       Color256,
+	ColorSettingTrueColor: ColorTrueColor,
+}
+
+// ValidateColorSetting checks a value of the config "color" setting.
+func ValidateColorSetting(setting string) error {
+	switch setting {
+	case "", ColorSettingAuto, ColorSettingAlways:
+		return nil
+	}
+	if _, ok := colorSettings[setting]; !ok {
+		return fmt.Errorf("unknown color setting %q", setting)
+	}
+	return nil
+}
+
+// DetectColorMode decides how much color to use. The --no-color flag wins,
+// then the config setting, then NO_COLOR, FORCE_COLOR, CLICOLOR_FORCE and
+// CLICOLOR. Output is colored by default even though stdout is a pipe,
+// because Claude Code renders the escape codes; the depth comes from
+// COLORTERM and TERM.
+func DetectColorMode(noColorFlag bool, setting string) ColorMode {
+	if noColorFlag {
+		return ColorNone
+	}
+	if mode, ok := colorSettings[setting]; ok {
+		return mode
+	}
+	if setting == ColorSettingAlways {
+		return terminalColorDepth()
+	}
+
+	if os.Getenv("NO_COLOR") != "" {
+		return ColorNone
+	}
+	switch force := os.Getenv("FORCE_COLOR"); force {
+	case "":
+	case "0", "false":
+		return ColorNone
+	case "1":
+		return Color16
+	case "2":
+		return Color256
+	case "3":
+		return ColorTrueColor
+	default:
+		return terminalColorDepth()
+	}
+	if force := os.Getenv("CLICOLOR_FORCE"); force != "" && force != "0" {
+		return terminalColorDepth()
+	}
+	if os.Getenv("CLICOLOR") == "0" || os.Getenv("TERM") == "dumb" {
+		return ColorNone
+	}
+	return terminalColorDepth()
+}
+
+func terminalColorDepth() ColorMode {
+	switch colorterm := strings.ToLower(os.Getenv("COLORTERM")); colorterm {
+	case "truecolor", "24bit":
+		return ColorTrueColor
+	}
+	if strings.Contains(os.Getenv("TERM"), "256color") {
+		return Color256
+	}
+	return Color16
+}
+
+// ApplyColorMode strips or downsamples the colors of every section so that
+// all renderers stay within mode.
+func (s *StatusLine) ApplyColorMode(mode ColorMode) {
+	s.NoColor = mode == ColorNone
+	for _, sections := range [][]Section{s.Sections, s.Right} {
+		for i := range sections {
+			sections[i].Color = DownsampleColor(sections[i].Color, mode)
+			sections[i].Background = DownsampleColor(sections[i].Background, mode)
+			for j := range sections[i].Spans {
+				sections[i].Spans[j].Color = DownsampleColor(sections[i].Spans[j].Color, mode)
+			}
+		}
+	}
+}
+
+// DownsampleColor converts c to the nearest color mode can show, or nil
+// when mode has no colors.
+func DownsampleColor(c *color.Color, mode ColorMode) *color.Color {
+	if c == nil || mode == ColorNone {
+		return nil
+	}
+	style := TextStyleOf(c)
+	downsampled := style.Downsample(mode)
+	if downsampled == style {
+		return c
+	}
+	return downsampled.Color()
+}
+
+// Downsample converts colors the mode cannot show to their nearest
+// equivalent.
+func (s TextStyle) Downsample(mode ColorMode) TextStyle {
+	s.Foreground = s.Foreground.Downsample(mode)
+	s.Background = s.Background.Downsample(mode)
+	return s
+}
+
+func (c TermColor) Downsample(mode ColorMode) TermColor {
+	if !c.Set {
+		return c
+	}
+	switch mode {
+	case ColorNone:
+		return TermColor{}
+	case Color256:
+		if c.IsRGB() {
+			return TermColor{Set: true, Index: nearestPaletteIndex(c.rgb(), 16, 256, nil)}
+		}
+	case Color16:
+		if c.IsRGB() || c.Index >= 16 {
+			return TermColor{Set: true, Index: nearestBasicColor(c.rgb())}
+		}
+	}
+	return c
+}
+
+// rgb returns the color's components, looking palette colors up in the
+// xterm defaults.
+func (c TermColor) rgb() [3]int {
+	if c.IsRGB() {
+		var rgb [3]int
+		fmt.Sscanf(c.RGB, "#%02x%02x%02x", &rgb[0], &rgb[1], &rgb[2])
+		return rgb
+	}
+	return paletteRGB(c.Index)
+}
+
+var basicPalette = [16][3]int{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+var cubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+func paletteRGB(index int) [3]int {
+	switch {
+	case index < 16:
+		return basicPalette[index]
+	case index < 232:
+		index -= 16
+		return [3]int{cubeLevels[index/36], cubeLevels[index/6%6], cubeLevels[index%6]}
+	default:
+		gray := 8 + (index-232)*10
+		return [3]int{gray, gray, gray}
+	}
+}
+
+// neutralColors are black, white and the two greys of the basic palette.
+var neutralColors = []int{0, 7, 8, 15}
+
+// minChromaticSaturation is the saturation above which a color is kept
+// chromatic when mapped to the basic palette; muted theme colors would
+// otherwise all come out grey.
+const minChromaticSaturation = 0.15
+
+// nearestBasicColor maps rgb to one of the 16 basic colors.
+func nearestBasicColor(rgb [3]int) int {
+	high, low := max(rgb[0], rgb[1], rgb[2]), min(rgb[0], rgb[1], rgb[2])
+	if high == 0 || float64(high-low)/float64(high) < minChromaticSaturation {
+		return nearestPaletteIndex(rgb, 0, 16, nil)
+	}
+	return nearestPaletteIndex(rgb, 0, 16, neutralColors)
+}
+
+// nearestPaletteIndex finds the palette entry in [from, to), other than
+// those in skip, closest to rgb.
+func nearestPaletteIndex(rgb [3]int, from, to int, skip []int) int {
+	best, bestDistance := from, math.MaxInt
+	for index := from; index < to; index++ {
+		if slices.Contains(skip, index) {
+			continue
+		}
+		candidate := paletteRGB(index)
+		distance := 0
+		for i := range rgb {
+			d := rgb[i] - candidate[i]
+			distance += d * d
+		}
+		if distance < bestDistance {
+			best, bestDistance = index, distance
+		}
+	}
+	return best
+}
@@ -0,0 +1,94 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectColorMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		flag     bool
+		setting  string
+		env      map[string]string
+		expected ColorMode
+	}{
+		{name: "default", expected: Color16},
+		{name: "256 color term", env: map[string]string{"TERM": "xterm-256color"}, expected: Color256},
+		{name: "truecolor", env: map[string]string{"COLORTERM": "truecolor", "TERM": "xterm-256color"}, expected: ColorTrueColor},
+		{name: "24bit", env: map[string]string{"COLORTERM": "24bit"}, expected: ColorTrueColor},
+		{name: "dumb term", env: map[string]string{"TERM": "dumb"}, expected: ColorNone},
+		{name: "NO_COLOR", env: map[string]string{"NO_COLOR": "1", "COLORTERM": "truecolor"}, expected: ColorNone},
+		{name: "flag beats config", flag: true, setting: ColorSettingTrueColor, expected: ColorNone},
+		{name: "config beats NO_COLOR", setting: ColorSettingAlways, env: map[string]string{"NO_COLOR": "1", "TERM": "xterm-256color"}, expected: Color256},
+		{name: "config depth", setting: ColorSetting256, env: map[string]string{"COLORTERM": "truecolor"}, expected: Color256},
+		{name: "config never", setting: ColorSettingNever, env: map[string]string{"FORCE_COLOR": "3"}, expected: ColorNone},
+		{name: "config auto", setting: ColorSettingAuto, env: map[string]string{"NO_COLOR": "1"}, expected: ColorNone},
+		{name: "FORCE_COLOR level", env: map[string]string{"FORCE_COLOR": "2"}, expected: Color256},
+		{name: "FORCE_COLOR beats CLICOLOR", env: map[string]string{"FORCE_COLOR": "true", "CLICOLOR": "0", "COLORTERM": "truecolor"}, expected: ColorTrueColor},
+		{name: "FORCE_COLOR off", env: map[string]string{"FORCE_COLOR": "0"}, expected: ColorNone},
+		{name: "CLICOLOR off", env: map[string]string{"CLICOLOR": "0"}, expected: ColorNone},
+		{name: "CLICOLOR_FORCE", env: map[string]string{"CLICOLOR_FORCE": "1", "CLICOLOR": "0"}, expected: Color16},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"NO_COLOR", "FORCE_COLOR", "CLICOLOR_FORCE", "CLICOLOR", "TERM", "COLORTERM"} {
+				t.Setenv(name, tt.env[name])
+			}
+			assert.Equal(t, tt.expected, DetectColorMode(tt.flag, tt.setting))
+		})
+	}
+}
+
+func TestTermColorDownsample(t *testing.T) {
+	orange := TermColor{Set: true, RGB: "#ff8700"}
+	assert.Equal(t, orange, orange.Downsample(ColorTrueColor))
+	assert.Equal(t, TermColor{Set: true, Index: 208}, orange.Downsample(Color256))
+	assert.Equal(t, TermColor{Set: true, Index: 3}, orange.Downsample(Color16))
+	assert.Equal(t, TermColor{}, orange.Downsample(ColorNone))
+
+	gray := TermColor{Set: true, Index: 244}
+	assert.Equal(t, gray, gray.Downsample(Color256))
+	assert.Equal(t, TermColor{Set: true, Index: 8}, gray.Downsample(Color16))
+
+	mutedAqua := TermColor{Set: true, RGB: "#83a598"}
+	assert.Equal(t, TermColor{Set: true, Index: 12}, mutedAqua.Downsample(Color16), "muted colors should not turn grey")
+
+	red := TermColor{Set: true, Index: 1}
+	assert.Equal(t, red, red.Downsample(Color16))
+	assert.Equal(t, TermColor{}, TermColor{}.Downsample(Color16))
+}
+
+func TestApplyColorMode(t *testing.T) {
+	green := color.New(color.FgGreen)
+	newLine := func() *StatusLine {
+		return &StatusLine{
+			Sections: []Section{{Content: "a", Color: green, Spans: []Span{{Text: "a", Color: color.RGB(255, 0, 0)}}}},
+			Right:    []Section{{Content: "b", Color: color.RGB(255, 135, 0), Background: color.BgRGB(0, 0, 0)}},
+		}
+	}
+
+	line := newLine()
+	line.ApplyColorMode(ColorTrueColor)
+	assert.False(t, line.NoColor)
+	assert.Same(t, green, line.Sections[0].Color)
+	assert.Equal(t, color.RGB(255, 135, 0), line.Right[0].Color)
+
+	line = newLine()
+	line.ApplyColorMode(Color256)
+	assert.Same(t, green, line.Sections[0].Color, "basic colors are left alone")
+	assert.Equal(t, color.New(38, 5, 196), line.Sections[0].Spans[0].Color)
+	assert.Equal(t, color.New(38, 5, 208), line.Right[0].Color)
+	assert.Equal(t, color.New(48, 5, 16), line.Right[0].Background)
+
+	line = newLine()
+	line.ApplyColorMode(ColorNone)
+	assert.True(t, line.NoColor)
+	assert.Nil(t, line.Sections[0].Color)
+	assert.Nil(t, line.Sections[0].Spans[0].Color)
+	assert.Nil(t, line.Right[0].Color)
+	assert.Nil(t, line.Right[0].Background)
+}
@@ -0,0 +1,117 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	FormatPowerline        = "powerline"
+	FormatPowerlineRounded = "powerline-rounded"
+	FormatPowerlineASCII   = "powerline-ascii"
+)
+
+const sgrReset = "\x1b[0m"
+
+// powerlineGlyphs are drawn between powerline segments. Hard separators mark a
+// change of background and take the colors of both neighbours; soft
+// separators divide segments that share a background. Start, if set, caps the
+// left end of the line.
+type powerlineGlyphs struct {
+	Start string
+	Hard  string
+	Soft  string
+}
+
+var (
+	powerlineArrow   = powerlineGlyphs{Hard: "", Soft: ""}
+	powerlineRounded = powerlineGlyphs{Start: "", Hard: "", Soft: ""}
+	powerlineASCII   = powerlineGlyphs{Hard: ">", Soft: "|"}
+)
+
+// powerlineRenderer draws each section as a block of background color. The
+// status line's separator is not used; the glyphs take its place. The right
+// group is drawn as a second chain of segments.
+func powerlineRenderer(glyphs powerlineGlyphs) Renderer {
+	return func(w io.Writer, line *StatusLine) error {
+		left := glyphs.chain(line.Sections, line.NoColor)
+		if len(line.Right) > 0 {
+			right := glyphs.chain(line.Right, line.NoColor)
+			gap := 1
+			if line.Columns > 0 {
+				gap = max(line.Columns-VisibleWidth(left)-VisibleWidth(right), 1)
+			}
+			left += strings.Repeat(" ", gap) + right
+		}
+
+		_, err := fmt.Fprintln(w, left)
+		return err
+	}
+}
+
+// width is the number of cells powerlineRenderer draws line in, with the
+// right group at its minimum gap.
+func (g powerlineGlyphs) width(line *StatusLine) int {
+	width := VisibleWidth(g.chain(line.Sections, true))
+	if len(line.Right) > 0 {
+		width += 1 + VisibleWidth(g.chain(line.Right, true))
+	}
+	return width
+}
+
+// chain draws sections as one run of segments. Without color only the text
+// and glyphs are written.
+func (g powerlineGlyphs) chain(sections []Section, noColor bool) string {
+	styles := make([]TextStyle, len(sections))
+	for i, section := range sections {
+		styles[i] = powerlineStyle(section)
+	}
+
+	var b strings.Builder
+	paint := func(style TextStyle, text string) {
+		if noColor {
+			b.WriteString(text)
+		} else {
+			b.WriteString(style.SGR() + text + sgrReset)
+		}
+	}
+
+	if g.Start != "" && len(styles) > 0 {
+		paint(TextStyle{Foreground: styles[0].Background}, g.Start)
+	}
+	for i, section := range sections {
+		style := styles[i]
+		paint(style, " "+section.Text()+" ")
+
+		glyph, transition := g.Hard, TextStyle{Foreground: style.Background}
+		if i+1 < len(styles) {
+			if next := styles[i+1].Background; next == style.Background {
+				glyph, transition = g.Soft, TextStyle{Foreground: style.Foreground, Background: style.Background}
+			} else {
+				transition.Background = next
+			}
+		}
+		paint(transition, glyph)
+	}
+	return b.String()
+}
+
+// powerlineStyle fills in a background for sections that only set a
+// foreground color: the foreground becomes the background and the text is
+// drawn in black. Uncolored sections are drawn on grey.
+func powerlineStyle(section Section) TextStyle {
+	style := sectionStyle(section)
+	if style.Background.Set {
+		return style
+	}
+
+	if style.Foreground.Set {
+		style.Background = style.Foreground
+		style.Foreground = TermColor{Set: true, Index: 0}
+	} else {
+		style.Background = TermColor{Set: true, Index: 8}
+		style.Foreground = TermColor{Set: true, Index: 7}
+	}
+	return style
+}
@@ -0,0 +1,100 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPowerlineRenderer(t *testing.T) {
+	line := &StatusLine{
+		Separator: " | ",
+		Sections: []Section{
+			{Content: "dir", Color: color.New(color.FgCyan)},
+			{Content: "git", Color: color.New(color.FgWhite), Background: color.New(color.BgBlue)},
+			{Content: "model", Color: color.New(color.FgYellow), Background: color.New(color.BgBlue)},
+			{Content: "user"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		glyphs   powerlineGlyphs
+		expected string
+	}{
+		{
+			name:   "arrow",
+			glyphs: powerlineArrow,
+			expected: "\x1b[30;46m dir \x1b[0m\x1b[36;44m\x1b[0m" +
+				"\x1b[37;44m git \x1b[0m\x1b[37;44m\x1b[0m" +
+				"\x1b[33;44m model \x1b[0m\x1b[34;100m\x1b[0m" +
+				"\x1b[37;100m user \x1b[0m\x1b[90m\x1b[0m\n",
+		},
+		{
+			name:   "rounded",
+			glyphs: powerlineRounded,
+			expected: "\x1b[36m\x1b[0m" +
+				"\x1b[30;46m dir \x1b[0m\x1b[36;44m\x1b[0m" +
+				"\x1b[37;44m git \x1b[0m\x1b[37;44m\x1b[0m" +
+				"\x1b[33;44m model \x1b[0m\x1b[34;100m\x1b[0m" +
+				"\x1b[37;100m user \x1b[0m\x1b[90m\x1b[0m\n",
+		},
+		{
+			name:   "ascii",
+			glyphs: powerlineASCII,
+			expected: "\x1b[30;46m dir \x1b[0m\x1b[36;44m>\x1b[0m" +
+				"\x1b[37;44m git \x1b[0m\x1b[37;44m|\x1b[0m" +
+				"\x1b[33;44m model \x1b[0m\x1b[34;100m>\x1b[0m" +
+				"\x1b[37;100m user \x1b[0m\x1b[90m>\x1b[0m\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, powerlineRenderer(tt.glyphs)(&buf, line))
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+
+	t.Run("no color", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, powerlineRenderer(powerlineASCII)(&buf, &StatusLine{NoColor: true, Sections: line.Sections}))
+		assert.Equal(t, " dir > git | model > user >\n", buf.String())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, powerlineRenderer(powerlineRounded)(&buf, &StatusLine{}))
+		assert.Equal(t, "\n", buf.String())
+	})
+}
+
+func TestPowerlineStyle(t *testing.T) {
+	bold := powerlineStyle(Section{Color: color.New(color.Bold, color.FgHiGreen)})
+	assert.Equal(t, TextStyle{
+		Foreground: TermColor{Set: true, Index: 0},
+		Background: TermColor{Set: true, Index: 10},
+		Bold:       true,
+	}, bold)
+
+	explicit := powerlineStyle(Section{Background: color.New(color.BgRed)})
+	assert.Equal(t, TextStyle{Background: TermColor{Set: true, Index: 1}}, explicit)
+}
+
+func TestPowerlineRendererRightGroup(t *testing.T) {
+	line := &StatusLine{
+		Sections: []Section{{Content: "a", Background: color.New(color.BgRed)}},
+		Right:    []Section{{Content: "b", Background: color.New(color.BgBlue)}},
+		Columns:  12,
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, powerlineRenderer(powerlineASCII)(&buf, line))
+	assert.Equal(t, "\x1b[41m a \x1b[0m\x1b[31m>\x1b[0m"+"    "+"\x1b[44m b \x1b[0m\x1b[34m>\x1b[0m\n", buf.String())
+	assert.Equal(t, 12, VisibleWidth(strings.TrimSuffix(buf.String(), "\n")))
+}
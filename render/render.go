@@ -0,0 +1,168 @@
+package render
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatPlain = "plain"
+	FormatTmux  = "tmux"
+	FormatZsh   = "zsh"
+)
+
+// Renderer writes a finished status line to w in one output format.
+type Renderer func(w io.Writer, line *StatusLine) error
+
+var renderers = map[string]Renderer{
+	FormatText:  renderText,
+	FormatJSON:  renderJSON,
+	FormatPlain: renderPlain,
+	FormatTmux:  renderTmux,
+	FormatZsh:   renderZsh,
+
+	FormatPowerline:        powerlineRenderer(powerlineArrow),
+	FormatPowerlineRounded: powerlineRenderer(powerlineRounded),
+	FormatPowerlineASCII:   powerlineRenderer(powerlineASCII),
+}
+
+// GetRenderer returns the renderer for format.
+func GetRenderer(format string) (Renderer, error) {
+	render, ok := renderers[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q (want one of %s)", format, strings.Join(Formats(), ", "))
+	}
+	return render, nil
+}
+
+// Formats lists the supported output formats in sorted order.
+func Formats() []string {
+	formats := make([]string, 0, len(renderers))
+	for format := range renderers {
+		formats = append(formats, format)
+	}
+	slices.Sort(formats)
+	return formats
+}
+
+func renderText(w io.Writer, line *StatusLine) error {
+	_, err := fmt.Fprintln(w, line.String())
+	return err
+}
+
+func renderPlain(w io.Writer, line *StatusLine) error {
+	return renderMarkup(w, line, Section.Text, nil)
+}
+
+// renderTmux writes tmux status-left/status-right markup. "#" is doubled so
+// content is never read as a format.
+func renderTmux(w io.Writer, line *StatusLine) error {
+	escape := strings.NewReplacer("#", "##").Replace
+	return renderMarkup(w, line, func(section Section) string {
+		var b strings.Builder
+		for _, run := range section.runs() {
+			text := escape(run.Text)
+			if attrs := tmuxAttributes(runStyle(section, run.Color)); len(attrs) > 0 {
+				text = "#[" + strings.Join(attrs, ",") + "]" + text + "#[default]"
+			}
+			b.WriteString(text)
+		}
+		return b.String()
+	}, escape)
+}
+
+func tmuxAttributes(style TextStyle) []string {
+	var attrs []string
+	if style.Foreground.Set {
+		attrs = append(attrs, "fg="+tmuxColor(style.Foreground))
+	}
+	if style.Background.Set {
+		attrs = append(attrs, "bg="+tmuxColor(style.Background))
+	}
+	for _, attr := range []struct {
+		set  bool
+		name string
+	}{
+		{style.Bold, "bold"},
+		{style.Dim, "dim"},
+		{style.Italic, "italics"},
+		{style.Underline, "underscore"},
+	} {
+		if attr.set {
+			attrs = append(attrs, attr.name)
+		}
+	}
+	return attrs
+}
+
+// renderZsh writes zsh prompt escapes for use in PROMPT or RPROMPT. "%" is
+// doubled so content is never read as an escape.
+func renderZsh(w io.Writer, line *StatusLine) error {
+	escape := strings.NewReplacer("%", "%%").Replace
+	return renderMarkup(w, line, func(section Section) string {
+		var b strings.Builder
+		for _, run := range section.runs() {
+			b.WriteString(zshEscapes(runStyle(section, run.Color), escape(run.Text)))
+		}
+		return b.String()
+	}, escape)
+}
+
+func zshEscapes(style TextStyle, text string) string {
+	if style.Foreground.Set {
+		text = "%F{" + zshColor(style.Foreground) + "}" + text + "%f"
+	}
+	if style.Background.Set {
+		text = "%K{" + zshColor(style.Background) + "}" + text + "%k"
+	}
+	if style.Bold {
+		text = "%B" + text + "%b"
+	}
+	if style.Underline {
+		text = "%U" + text + "%u"
+	}
+	return text
+}
+
+// renderMarkup lays out the sections formatted by format, escaping the
+// separator for the target syntax when escape is set.
+func renderMarkup(w io.Writer, line *StatusLine, format func(Section) string, escape func(string) string) error {
+	separator := cmp.Or(line.Separator, DefaultSeparator)
+	if escape != nil {
+		separator = escape(separator)
+	}
+
+	_, err := fmt.Fprintln(w, line.join(format, separator))
+	return err
+}
+
+// renderJSON writes the sections without any color so consumers get the
+// semantic level and raw values instead of escape codes.
+func renderJSON(w io.Writer, line *StatusLine) error {
+	if line.Sections == nil {
+		empty := *line
+		empty.Sections = []Section{}
+		line = &empty
+	}
+	return json.NewEncoder(w).Encode(line)
+}
+
+// ErrorSection stands in for a section called name that failed to build. It
+// shows only a marker so one failure cannot crowd out the rest of the line;
+// the error itself is kept in Values for machine readable output.
+func ErrorSection(name string, err error) Section {
+	return Section{
+		Name:    name,
+		Icon:    "⚠",
+		Content: name,
+		Color:   LevelError.Color(),
+		Level:   LevelError,
+		Values:  map[string]any{"error": err.Error()},
+	}
+}
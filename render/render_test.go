@@ -0,0 +1,169 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRenderer(t *testing.T) {
+	for _, format := range []string{FormatText, FormatJSON, FormatPlain, FormatTmux, FormatZsh, FormatPowerline, FormatPowerlineRounded, FormatPowerlineASCII} {
+		render, err := GetRenderer(format)
+		require.NoError(t, err, format)
+		assert.NotNil(t, render)
+	}
+
+	_, err := GetRenderer("yaml")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown format "yaml"`)
+	assert.Contains(t, err.Error(), "json, plain, powerline, powerline-ascii, powerline-rounded, text, tmux, zsh")
+}
+
+func TestRenderText(t *testing.T) {
+	line := &StatusLine{
+		Separator: " | ",
+		Sections:  []Section{{Content: "first"}, {Icon: "★", Content: "second"}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, renderText(&buf, line))
+	assert.Equal(t, "first | ★ second\n", buf.String())
+}
+
+func TestRenderMarkup(t *testing.T) {
+	line := &StatusLine{
+		Separator: " # ",
+		Sections: []Section{
+			{Icon: "★", Content: "100%", Color: color.New(color.Bold, color.FgRed)},
+			{Content: "#1", Color: color.New(color.FgHiBlue, color.BgBlack)},
+			{Content: "plain"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		render   Renderer
+		expected string
+	}{
+		{
+			name:     "plain",
+			render:   renderPlain,
+			expected: "★ 100% # #1 # plain\n",
+		},
+		{
+			name:     "tmux",
+			render:   renderTmux,
+			expected: "#[fg=red,bold]★ 100%#[default] ## #[fg=brightblue,bg=black]##1#[default] ## plain\n",
+		},
+		{
+			name:     "zsh",
+			render:   renderZsh,
+			expected: "%B%F{red}★ 100%%%f%b # %K{black}%F{12}#1%f%k # plain\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, tt.render(&buf, line))
+			assert.Equal(t, tt.expected, buf.String())
+			assert.NotContains(t, buf.String(), "\x1b[")
+		})
+	}
+}
+
+func TestRenderMarkupSpans(t *testing.T) {
+	line := &StatusLine{
+		Sections: []Section{{
+			Content: "#% ok",
+			Color:   color.New(color.FgGreen),
+			Spans:   []Span{{Text: "#", Color: color.New(color.FgRed)}, {Text: "%", Color: color.New(color.FgYellow)}, {Text: " ok"}},
+		}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, renderTmux(&buf, line))
+	assert.Equal(t, "#[fg=red]###[default]#[fg=yellow]%#[default]#[fg=green] ok#[default]\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, renderZsh(&buf, line))
+	assert.Equal(t, "%F{red}#%f%F{yellow}%%%f%F{green} ok%f\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, renderJSON(&buf, line))
+	assert.Contains(t, buf.String(), `"content":"#% ok"`)
+}
+
+func TestRenderJSON(t *testing.T) {
+	t.Run("sections", func(t *testing.T) {
+		line := &StatusLine{
+			Separator: " | ",
+			Sections: []Section{
+				{Name: "dir", Icon: "D", Content: "project", Color: color.New(color.FgCyan)},
+				{
+					Name:    "context",
+					Content: "⛁⛁⛁⛁⛁⛁⛁⛀⛶⛶ 150k/200k (75%) ",
+					Color:   LevelWarn.Color(),
+					Level:   LevelWarn,
+					Values:  map[string]any{"tokens": 150000, "max_tokens": 200000, "percentage": 75.0},
+				},
+			},
+		}
+
+		var buf bytes.Buffer
+		require.NoError(t, renderJSON(&buf, line))
+		assert.NotContains(t, buf.String(), "\x1b[", "JSON output should not contain escape codes")
+
+		var decoded struct {
+			Separator string `json:"separator"`
+			Sections  []struct {
+				Name    string         `json:"name"`
+				Icon    string         `json:"icon"`
+				Content string         `json:"content"`
+				Level   string         `json:"level"`
+				Values  map[string]any `json:"values"`
+			} `json:"sections"`
+		}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+
+		assert.Equal(t, " | ", decoded.Separator)
+		require.Len(t, decoded.Sections, 2)
+		assert.Equal(t, "dir", decoded.Sections[0].Name)
+		assert.Equal(t, "D", decoded.Sections[0].Icon)
+		assert.Equal(t, "project", decoded.Sections[0].Content)
+		assert.Empty(t, decoded.Sections[0].Level)
+
+		assert.Equal(t, "context", decoded.Sections[1].Name)
+		assert.Equal(t, "warn", decoded.Sections[1].Level)
+		assert.Equal(t, 150000.0, decoded.Sections[1].Values["tokens"])
+		assert.Equal(t, 200000.0, decoded.Sections[1].Values["max_tokens"])
+		assert.Equal(t, 75.0, decoded.Sections[1].Values["percentage"])
+	})
+
+	t.Run("right group", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, renderJSON(&buf, &StatusLine{Right: []Section{{Name: "user", Content: "me"}}}))
+		assert.JSONEq(t, `{"separator": "", "sections": [], "right": [{"name": "user", "content": "me"}]}`, buf.String())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, renderJSON(&buf, &StatusLine{}))
+		assert.JSONEq(t, `{"separator": "", "sections": []}`, buf.String())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		var buf bytes.Buffer
+		line := &StatusLine{Sections: []Section{ErrorSection("event", errors.New("unexpected EOF"))}}
+		require.NoError(t, renderJSON(&buf, line))
+		assert.JSONEq(t, `{
+			"separator": "",
+			"sections": [{"name": "event", "icon": "⚠", "content": "event", "level": "error", "values": {"error": "unexpected EOF"}}]
+		}`, buf.String())
+	})
+}
@@ -0,0 +1,161 @@
+// Package render lays out status line sections and writes them in each
+// output format: ANSI text, JSON, tmux and zsh markup, and powerline.
+package render
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// DefaultSeparator is put between sections when a StatusLine has no
+// Separator.
+const DefaultSeparator = " | "
+
+// StatusLine is one row of output. Sections are left aligned; Right sections
+// follow them, pushed to the right edge when Columns, the width of the
+// terminal, is known. NoColor stops renderers that draw their own colors
+// from emitting any.
+type StatusLine struct {
+	Separator string    `json:"separator"`
+	Sections  []Section `json:"sections"`
+	Right     []Section `json:"right,omitempty"`
+	Columns   int       `json:"-"`
+	NoColor   bool      `json:"-"`
+}
+
+// Section is one part of the status line. Name, Level and Values describe the
+// section for machine readable output; Color and Background are only used by
+// renderers that draw colors, and Background is optional. When the line is
+// too wide, sections with the lowest Priority are abbreviated to Short, if
+// set, and then dropped. Spans, if set, split Content into separately
+// colored parts.
+type Section struct {
+	Name       string         `json:"name"`
+	Icon       string         `json:"icon,omitempty"`
+	Content    string         `json:"content"`
+	Short      string         `json:"-"`
+	Color      *color.Color   `json:"-"`
+	Background *color.Color   `json:"-"`
+	Spans      []Span         `json:"-"`
+	Level      Level          `json:"level,omitempty"`
+	Priority   int            `json:"priority,omitempty"`
+	Values     map[string]any `json:"values,omitempty"`
+}
+
+// Span is part of a section's content drawn in its own color. A nil Color
+// uses the section's color.
+type Span struct {
+	Text  string
+	Color *color.Color
+}
+
+// Level is the semantic state of a section, independent of how it is colored.
+type Level string
+
+const (
+	LevelOK    Level = "ok"
+	LevelWarn  Level = "warn"
+	LevelCrit  Level = "crit"
+	LevelError Level = "error"
+)
+
+// ThresholdLevel grades value against warn and crit thresholds.
+func ThresholdLevel(value, warn, crit float64) Level {
+	if value < warn {
+		return LevelOK
+	} else if value < crit {
+		return LevelWarn
+	} else {
+		return LevelCrit
+	}
+}
+
+// Color returns the default color for the level.
+func (l Level) Color() *color.Color {
+	switch l {
+	case LevelOK:
+		return color.New(color.FgGreen)
+	case LevelWarn:
+		return color.New(color.FgYellow)
+	case LevelCrit, LevelError:
+		return color.New(color.FgRed)
+	default:
+		return nil
+	}
+}
+
+func (s *StatusLine) String() string {
+	return s.join(Section.String, cmp.Or(s.Separator, DefaultSeparator))
+}
+
+// join lays out the sections formatted by format, with separator being the
+// line's separator in the target syntax. Without a known width the right
+// group simply follows the left one after a separator.
+func (s *StatusLine) join(format func(Section) string, separator string) string {
+	left := joinSections(s.Sections, format, separator)
+	if len(s.Right) == 0 {
+		return left
+	}
+
+	right := joinSections(s.Right, format, separator)
+	if s.Columns <= 0 {
+		if len(s.Sections) == 0 {
+			return right
+		}
+		return left + separator + right
+	}
+
+	visibleSeparator := cmp.Or(s.Separator, DefaultSeparator)
+	used := groupWidth(s.Sections, visibleSeparator) + groupWidth(s.Right, visibleSeparator)
+	return left + strings.Repeat(" ", max(s.Columns-used, 1)) + right
+}
+
+func joinSections(sections []Section, format func(Section) string, separator string) string {
+	parts := make([]string, len(sections))
+	for i, section := range sections {
+		parts[i] = format(section)
+	}
+	return strings.Join(parts, separator)
+}
+
+func (s Section) String() string {
+	var content string
+	for _, run := range s.runs() {
+		if run.Color != nil {
+			content += run.Color.Sprint(run.Text)
+		} else {
+			content += run.Text
+		}
+	}
+	if s.Background != nil {
+		content = s.Background.Sprint(content)
+	}
+	return content
+}
+
+// Text returns the icon and content without any color.
+func (s Section) Text() string {
+	if s.Icon != "" {
+		return fmt.Sprintf("%s %s", s.Icon, s.Content)
+	}
+	return s.Content
+}
+
+// runs splits the section's text into parts with a resolved color each.
+func (s Section) runs() []Span {
+	if len(s.Spans) == 0 {
+		return []Span{{Text: s.Text(), Color: s.Color}}
+	}
+
+	var runs []Span
+	if s.Icon != "" {
+		runs = append(runs, Span{Text: s.Icon + " ", Color: s.Color})
+	}
+	for _, span := range s.Spans {
+		runs = append(runs, Span{Text: span.Text, Color: cmp.Or(span.Color, s.Color)})
+	}
+	return runs
+}
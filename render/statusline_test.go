@@ -1,11 +1,11 @@
-package main
+package render
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/fatih/color"
 	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/require"
 )
 
 func TestStatusLineString(t *testing.T) {
@@ -117,33 +117,59 @@ func TestSectionWithColor(t *testing.T) {
 	assert.Contains(t, result, "\x1b[", "Colored section should contain ANSI escape codes")
 }
 
-func TestNewStatusLineFromEvent(t *testing.T) {
-	t.Run("successful creation", func(t *testing.T) {
-		event := &StatusHookEvent{
-			TranscriptPath: "/tmp/nonexistent.jsonl",
-			Model: Model{
-				DisplayName: "Claude 3",
-			},
-			Workspace: Workspace{
-				CurrentDir: "/home/user/project",
-			},
-			Cost: Cost{
-				TotalCostUSD: 0.0542,
-			},
-		}
+func TestSectionWithBackground(t *testing.T) {
+	color.NoColor = false
+	defer func() { color.NoColor = true }()
+
+	section := Section{
+		Content:    "block",
+		Color:      color.New(color.FgBlack),
+		Background: color.New(color.BgYellow),
+	}
+
+	assert.Equal(t, "\x1b[43m\x1b[30mblock\x1b[0m\x1b[0m", section.String())
+}
+
+func TestSectionWithSpans(t *testing.T) {
+	color.NoColor = false
+	defer func() { color.NoColor = true }()
+
+	section := Section{
+		Icon:    "*",
+		Content: "ab c",
+		Color:   color.New(color.FgGreen),
+		Spans:   []Span{{Text: "a", Color: color.New(color.FgRed)}, {Text: "b", Color: color.New(color.FgYellow)}, {Text: " c"}},
+	}
+
+	assert.Equal(t, "\x1b[32m* \x1b[0m\x1b[31ma\x1b[0m\x1b[33mb\x1b[0m\x1b[32m c\x1b[0m", section.String())
+}
+
+func TestThresholdLevel(t *testing.T) {
+	assert.Equal(t, LevelOK, ThresholdLevel(10, 60, 80))
+	assert.Equal(t, LevelWarn, ThresholdLevel(60, 60, 80))
+	assert.Equal(t, LevelCrit, ThresholdLevel(95, 60, 80))
+
+	assert.Equal(t, color.New(color.FgYellow), LevelWarn.Color())
+	assert.Nil(t, Level("").Color())
+}
+
+func TestStatusLineRightGroup(t *testing.T) {
+	line := StatusLine{
+		Separator: " | ",
+		Sections:  []Section{{Content: "left"}, {Content: "more"}},
+		Right:     []Section{{Content: "right"}},
+	}
+	assert.Equal(t, "left | more | right", line.String())
 
-		statusLine, err := NewStatusLineFromEvent(event)
-		require.NoError(t, err)
-		require.NotNil(t, statusLine)
+	line.Columns = 30
+	assert.Equal(t, "left | more"+strings.Repeat(" ", 14)+"right", line.String())
+	assert.Equal(t, 30, VisibleWidth(line.String()))
 
-		assert.Equal(t, " | ", statusLine.Separator)
-		assert.Len(t, statusLine.Sections, 5, "Should have 5 sections: user, dir, model, cost, context")
+	line.Sections = nil
+	assert.Equal(t, strings.Repeat(" ", 25)+"right", line.String())
 
-		assert.Contains(t, statusLine.Sections[0].Content, "@")
-		assert.Equal(t, "project", statusLine.Sections[1].Content)
-		assert.Equal(t, "Claude 3", statusLine.Sections[2].Content)
-		assert.Equal(t, "0.0542", statusLine.Sections[3].Content)
-	})
+	line.Columns = 0
+	assert.Equal(t, "right", line.String())
 }
 
 func TestStatusLineDefaultSeparator(t *testing.T) {
@@ -0,0 +1,217 @@
+package render
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// TextStyle is a color.Color broken down into the attributes that non-ANSI
+// renderers such as tmux and zsh need to express it in their own syntax.
+type TextStyle struct {
+	Foreground TermColor
+	Background TermColor
+	Bold       bool
+	Dim        bool
+	Italic     bool
+	Underline  bool
+}
+
+// TermColor is a terminal color: one of the 16 basic colors (0-7 normal,
+// 8-15 bright), an entry of the 256 color palette, or a 24-bit RGB value.
+// The zero value means the default color.
+type TermColor struct {
+	Set   bool
+	Index int
+	RGB   string
+}
+
+func (c TermColor) IsRGB() bool {
+	return c.Set && c.RGB != ""
+}
+
+// sectionStyle combines a section's foreground and background colors.
+func sectionStyle(section Section) TextStyle {
+	return runStyle(section, section.Color)
+}
+
+// runStyle is the style of a part of section drawn in c.
+func runStyle(section Section, c *color.Color) TextStyle {
+	style := TextStyleOf(c)
+	if background := TextStyleOf(section.Background).Background; background.Set {
+		style.Background = background
+	}
+	return style
+}
+
+var basicColorNames = [8]string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+var sgrPattern = regexp.MustCompile(`^\x1b\[([0-9;]*)m`)
+
+// TextStyleOf reads the SGR parameters of c. fatih/color keeps them private, so
+// they are recovered from the escape sequence it would print.
+func TextStyleOf(c *color.Color) TextStyle {
+	var style TextStyle
+	if c == nil {
+		return style
+	}
+
+	enabled := *c
+	enabled.EnableColor()
+	match := sgrPattern.FindStringSubmatch(enabled.Sprint(""))
+	if match == nil || match[1] == "" {
+		return style
+	}
+
+	var params []int
+	for _, field := range strings.Split(match[1], ";") {
+		param, err := strconv.Atoi(field)
+		if err != nil {
+			return style
+		}
+		params = append(params, param)
+	}
+
+	for i := 0; i < len(params); i++ {
+		switch param := params[i]; {
+		case param == int(color.Bold):
+			style.Bold = true
+		case param == int(color.Faint):
+			style.Dim = true
+		case param == int(color.Italic):
+			style.Italic = true
+		case param == int(color.Underline):
+			style.Underline = true
+		case param >= 30 && param <= 37:
+			style.Foreground = TermColor{Set: true, Index: param - 30}
+		case param >= 90 && param <= 97:
+			style.Foreground = TermColor{Set: true, Index: param - 90 + 8}
+		case param >= 40 && param <= 47:
+			style.Background = TermColor{Set: true, Index: param - 40}
+		case param >= 100 && param <= 107:
+			style.Background = TermColor{Set: true, Index: param - 100 + 8}
+		case param == 38 || param == 48:
+			extended, n := parseExtendedColor(params[i+1:])
+			if param == 38 {
+				style.Foreground = extended
+			} else {
+				style.Background = extended
+			}
+			i += n
+		}
+	}
+	return style
+}
+
+// parseExtendedColor parses the parameters following a 38 or 48, either
+// "5;n" for the 256 color palette or "2;r;g;b", and returns how many it used.
+func parseExtendedColor(params []int) (TermColor, int) {
+	if len(params) >= 2 && params[0] == 5 {
+		return TermColor{Set: true, Index: params[1]}, 2
+	}
+	if len(params) >= 4 && params[0] == 2 {
+		return TermColor{Set: true, RGB: fmt.Sprintf("#%02x%02x%02x", params[1], params[2], params[3])}, 4
+	}
+	return TermColor{}, len(params)
+}
+
+// SGR returns the ANSI escape sequence that selects the style, or an empty
+// string for the default style.
+func (s TextStyle) SGR() string {
+	params := s.params()
+	if len(params) == 0 {
+		return ""
+	}
+
+	fields := make([]string, len(params))
+	for i, param := range params {
+		fields[i] = strconv.Itoa(param)
+	}
+	return "\x1b[" + strings.Join(fields, ";") + "m"
+}
+
+// Color converts the style back into a color.Color, or nil for the default
+// style.
+func (s TextStyle) Color() *color.Color {
+	params := s.params()
+	if len(params) == 0 {
+		return nil
+	}
+
+	attrs := make([]color.Attribute, len(params))
+	for i, param := range params {
+		attrs[i] = color.Attribute(param)
+	}
+	return color.New(attrs...)
+}
+
+func (s TextStyle) params() []int {
+	var params []int
+	for _, attr := range []struct {
+		set   bool
+		param color.Attribute
+	}{
+		{s.Bold, color.Bold},
+		{s.Dim, color.Faint},
+		{s.Italic, color.Italic},
+		{s.Underline, color.Underline},
+	} {
+		if attr.set {
+			params = append(params, int(attr.param))
+		}
+	}
+	params = append(params, s.Foreground.sgrParams(false)...)
+	return append(params, s.Background.sgrParams(true)...)
+}
+
+func (c TermColor) sgrParams(background bool) []int {
+	if !c.Set {
+		return nil
+	}
+
+	base := 30
+	if background {
+		base = 40
+	}
+	switch {
+	case c.IsRGB():
+		rgb := c.rgb()
+		return []int{base + 8, 2, rgb[0], rgb[1], rgb[2]}
+	case c.Index < 8:
+		return []int{base + c.Index}
+	case c.Index < 16:
+		return []int{base + 60 + c.Index - 8}
+	default:
+		return []int{base + 8, 5, c.Index}
+	}
+}
+
+// tmuxColor formats c for a tmux #[fg=...] style.
+func tmuxColor(c TermColor) string {
+	switch {
+	case c.IsRGB():
+		return c.RGB
+	case c.Index < 8:
+		return basicColorNames[c.Index]
+	case c.Index < 16:
+		return "bright" + basicColorNames[c.Index-8]
+	default:
+		return fmt.Sprintf("colour%d", c.Index)
+	}
+}
+
+// zshColor formats c for a zsh %F{...} prompt escape. Bright colors are
+// given by number since zsh only names the first eight.
+func zshColor(c TermColor) string {
+	switch {
+	case c.IsRGB():
+		return c.RGB
+	case c.Index < 8:
+		return basicColorNames[c.Index]
+	default:
+		return strconv.Itoa(c.Index)
+	}
+}
@@ -0,0 +1,114 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextStyleOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		color    *color.Color
+		expected TextStyle
+	}{
+		{name: "nil", color: nil, expected: TextStyle{}},
+		{name: "no attributes", color: color.New(), expected: TextStyle{}},
+		{
+			name:     "basic foreground",
+			color:    color.New(color.FgRed),
+			expected: TextStyle{Foreground: TermColor{Set: true, Index: 1}},
+		},
+		{
+			name:     "bright foreground and background",
+			color:    color.New(color.FgHiCyan, color.BgBlue),
+			expected: TextStyle{Foreground: TermColor{Set: true, Index: 14}, Background: TermColor{Set: true, Index: 4}},
+		},
+		{
+			name:     "attributes",
+			color:    color.New(color.Bold, color.Faint, color.Italic, color.Underline, color.FgGreen),
+			expected: TextStyle{Foreground: TermColor{Set: true, Index: 2}, Bold: true, Dim: true, Italic: true, Underline: true},
+		},
+		{
+			name:     "rgb",
+			color:    color.RGB(255, 128, 0).AddBgRGB(0, 0, 16),
+			expected: TextStyle{Foreground: TermColor{Set: true, RGB: "#ff8000"}, Background: TermColor{Set: true, RGB: "#000010"}},
+		},
+		{
+			name:     "256 palette",
+			color:    color.New(38, 5, 208),
+			expected: TextStyle{Foreground: TermColor{Set: true, Index: 208}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, TextStyleOf(tt.color))
+		})
+	}
+}
+
+func TestTextStyleOfIgnoresNoColor(t *testing.T) {
+	c := color.New(color.FgYellow)
+	c.DisableColor()
+
+	assert.Equal(t, TextStyle{Foreground: TermColor{Set: true, Index: 3}}, TextStyleOf(c))
+	assert.Equal(t, "text", c.Sprint("text"), "the original color should stay disabled")
+}
+
+func TestTextStyleSGR(t *testing.T) {
+	tests := []struct {
+		style    TextStyle
+		expected string
+	}{
+		{style: TextStyle{}, expected: ""},
+		{style: TextStyle{Foreground: TermColor{Set: true, Index: 1}}, expected: "\x1b[31m"},
+		{style: TextStyle{Bold: true, Foreground: TermColor{Set: true, Index: 12}, Background: TermColor{Set: true, Index: 0}}, expected: "\x1b[1;94;40m"},
+		{style: TextStyle{Foreground: TermColor{Set: true, Index: 208}, Background: TermColor{Set: true, Index: 9}}, expected: "\x1b[38;5;208;101m"},
+		{style: TextStyle{Foreground: TermColor{Set: true, RGB: "#ff8000"}}, expected: "\x1b[38;2;255;128;0m"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, tt.style.SGR())
+	}
+
+	c := color.New(color.Italic, color.FgHiMagenta, color.BgCyan)
+	assert.Equal(t, TextStyleOf(c), TextStyleOf(color.New(color.Italic, color.FgHiMagenta, color.BgCyan)))
+}
+
+func TestTextStyleColor(t *testing.T) {
+	assert.Nil(t, TextStyle{}.Color())
+
+	for _, c := range []*color.Color{
+		color.New(color.FgRed),
+		color.New(color.Bold, color.FgHiCyan, color.BgBlue),
+		color.New(38, 5, 208),
+		color.RGB(1, 2, 3),
+	} {
+		assert.Equal(t, c, TextStyleOf(c).Color())
+	}
+}
+
+func TestSectionStyle(t *testing.T) {
+	style := sectionStyle(Section{Color: color.New(color.FgRed, color.BgGreen), Background: color.New(color.BgBlue)})
+	assert.Equal(t, TextStyle{Foreground: TermColor{Set: true, Index: 1}, Background: TermColor{Set: true, Index: 4}}, style)
+}
+
+func TestTermColorNames(t *testing.T) {
+	tests := []struct {
+		color TermColor
+		tmux  string
+		zsh   string
+	}{
+		{color: TermColor{Set: true, Index: 1}, tmux: "red", zsh: "red"},
+		{color: TermColor{Set: true, Index: 13}, tmux: "brightmagenta", zsh: "13"},
+		{color: TermColor{Set: true, Index: 208}, tmux: "colour208", zsh: "208"},
+		{color: TermColor{Set: true, RGB: "#ff8000"}, tmux: "#ff8000", zsh: "#ff8000"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.tmux, tmuxColor(tt.color))
+		assert.Equal(t, tt.zsh, zshColor(tt.color))
+	}
+}
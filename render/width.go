@@ -0,0 +1,205 @@
+package render
+
+import (
+	"cmp"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Ellipsis marks text that was cut short.
+const Ellipsis = "…"
+
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// wideRanges are the code points terminals draw two cells wide: CJK text,
+// fullwidth forms and emoji.
+var wideRanges = []struct{ lo, hi rune }{
+	{0x1100, 0x115f},
+	{0x231a, 0x231b},
+	{0x23e9, 0x23ec},
+	{0x2614, 0x2615},
+	{0x26a1, 0x26a1},
+	{0x2705, 0x2705},
+	{0x274c, 0x274c},
+	{0x2e80, 0x303e},
+	{0x3041, 0x33ff},
+	{0x3400, 0x4dbf},
+	{0x4e00, 0x9fff},
+	{0xa000, 0xa4cf},
+	{0xac00, 0xd7a3},
+	{0xf900, 0xfaff},
+	{0xfe30, 0xfe4f},
+	{0xff00, 0xff60},
+	{0xffe0, 0xffe6},
+	{0x1f300, 0x1f64f},
+	{0x1f680, 0x1f6ff},
+	{0x1f900, 0x1f9ff},
+	{0x20000, 0x3fffd},
+}
+
+// VisibleWidth returns the number of terminal cells s occupies, ignoring ANSI
+// escape sequences.
+func VisibleWidth(s string) int {
+	width := 0
+	for _, r := range ansiPattern.ReplaceAllString(s, "") {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+func runeWidth(r rune) int {
+	if r < 0x20 || unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	for _, wide := range wideRanges {
+		if r >= wide.lo && r <= wide.hi {
+			return 2
+		}
+	}
+	return 1
+}
+
+// TruncateWidth shortens s to at most width cells, ending in an Ellipsis
+// when anything was cut.
+func TruncateWidth(s string, width int) string {
+	if VisibleWidth(s) <= width {
+		return s
+	}
+
+	var b strings.Builder
+	used := VisibleWidth(Ellipsis)
+	for _, r := range s {
+		if used+runeWidth(r) > width {
+			break
+		}
+		used += runeWidth(r)
+		b.WriteRune(r)
+	}
+	if used > width {
+		return ""
+	}
+	return b.String() + Ellipsis
+}
+
+// Width returns the visible width of the sections joined by the separator,
+// counting one separator between the left and right groups.
+func (s *StatusLine) Width() int {
+	separator := cmp.Or(s.Separator, DefaultSeparator)
+	return groupWidth(slices.Concat(s.Sections, s.Right), separator)
+}
+
+func groupWidth(sections []Section, separator string) int {
+	width := VisibleWidth(separator) * max(len(sections)-1, 0)
+	for _, section := range sections {
+		width += VisibleWidth(section.Text())
+	}
+	return width
+}
+
+// WidthFunc measures the visible width of a line as one renderer draws it.
+type WidthFunc func(line *StatusLine) int
+
+var widthFuncs = map[string]WidthFunc{
+	FormatPowerline:        powerlineArrow.width,
+	FormatPowerlineRounded: powerlineRounded.width,
+	FormatPowerlineASCII:   powerlineASCII.width,
+}
+
+// GetWidthFunc returns how wide the renderer for format draws a line.
+// Formats that join sections with the line's separator are measured by
+// (*StatusLine).Width.
+func GetWidthFunc(format string) WidthFunc {
+	if measure, ok := widthFuncs[format]; ok {
+		return measure
+	}
+	return (*StatusLine).Width
+}
+
+// Fit shrinks the status line until measure, or Width if measure is nil,
+// finds it no wider than width and records width as the line's Columns.
+// Sections are handled lowest priority first, right to left among equal
+// priorities: each is abbreviated to Short and, if that is not enough,
+// dropped. If the last section still does not fit its text is cut short. A
+// width of zero or less means no limit.
+func (s *StatusLine) Fit(width int, measure WidthFunc) {
+	if width <= 0 {
+		return
+	}
+	if measure == nil {
+		measure = (*StatusLine).Width
+	}
+	s.Columns = width
+	if measure(s) <= width {
+		return
+	}
+
+	all := slices.Concat(s.Sections, s.Right)
+	split := len(s.Sections)
+	dropped := make([]bool, len(all))
+	fits := func() bool {
+		s.Sections = keptSections(all[:split], dropped[:split])
+		s.Right = keptSections(all[split:], dropped[split:])
+		return measure(s) <= width
+	}
+
+	order := make([]int, len(all))
+	for i := range order {
+		order[i] = len(order) - 1 - i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(all[a].Priority, all[b].Priority)
+	})
+
+	// A section is abbreviated and then dropped before any section with a
+	// higher priority is touched.
+	for n, i := range order {
+		if all[i].Short != "" {
+			all[i].Content, all[i].Short, all[i].Spans = all[i].Short, "", nil
+			if fits() {
+				return
+			}
+		}
+		if n == len(order)-1 {
+			break
+		}
+		dropped[i] = true
+		if fits() {
+			return
+		}
+	}
+
+	// Only the last section is left, so everything but its content is the
+	// renderer's own decoration.
+	last := &all[order[len(order)-1]]
+	decoration := measure(s) - VisibleWidth(last.Content)
+	last.Content = TruncateWidth(last.Content, width-decoration)
+	last.Spans = nil
+	fits()
+}
+
+func keptSections(sections []Section, dropped []bool) []Section {
+	var kept []Section
+	for i, section := range sections {
+		if !dropped[i] {
+			kept = append(kept, section)
+		}
+	}
+	return kept
+}
+
+// TerminalWidth returns the width given by flag, falling back to the COLUMNS
+// environment variable. Zero means the width is unknown.
+func TerminalWidth(flag int) int {
+	if flag > 0 {
+		return flag
+	}
+	columns, err := strconv.Atoi(os.Getenv("COLUMNS"))
+	if err != nil || columns < 0 {
+		return 0
+	}
+	return columns
+}
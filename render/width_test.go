@@ -0,0 +1,211 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVisibleWidth(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected int
+	}{
+		{name: "ascii", input: "hello", expected: 5},
+		{name: "ansi escapes", input: "\x1b[1;31mred\x1b[0m", expected: 3},
+		{name: "box drawing", input: "⛁⛀⛶", expected: 3},
+		{name: "cjk", input: "日本", expected: 4},
+		{name: "emoji", input: "🤖 bot", expected: 6},
+		{name: "combining mark", input: "é", expected: 1},
+		{name: "empty", input: "", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, VisibleWidth(tt.input))
+		})
+	}
+}
+
+func TestTruncateWidth(t *testing.T) {
+	assert.Equal(t, "hello", TruncateWidth("hello", 5))
+	assert.Equal(t, "hel…", TruncateWidth("hello", 4))
+	assert.Equal(t, "日…", TruncateWidth("日本語", 4))
+	assert.Equal(t, "…", TruncateWidth("hello", 1))
+	assert.Equal(t, "", TruncateWidth("hello", 0))
+}
+
+func TestStatusLineFit(t *testing.T) {
+	newLine := func() *StatusLine {
+		return &StatusLine{
+			Separator: " | ",
+			Sections: []Section{
+				{Name: "user", Content: "me@host", Short: "me", Priority: 10},
+				{Name: "dir", Content: "project", Priority: 60},
+				{Name: "cost", Content: "1.2345", Short: "1.23", Priority: 50},
+				{Name: "context", Content: "⛁⛁⛶ 40k/200k (20%)", Short: "40k/200k (20%)", Priority: 90, Spans: []Span{{Text: "⛁⛁⛶ 40k/200k (20%)"}}},
+			},
+		}
+	}
+
+	names := func(line *StatusLine) []string {
+		var names []string
+		for _, section := range line.Sections {
+			names = append(names, section.Name)
+		}
+		return names
+	}
+
+	tests := []struct {
+		name     string
+		width    int
+		names    []string
+		contents []string
+	}{
+		{
+			name:     "no limit",
+			width:    0,
+			names:    []string{"user", "dir", "cost", "context"},
+			contents: []string{"me@host", "project", "1.2345", "⛁⛁⛶ 40k/200k (20%)"},
+		},
+		{
+			name:     "already fits",
+			width:    50,
+			names:    []string{"user", "dir", "cost", "context"},
+			contents: []string{"me@host", "project", "1.2345", "⛁⛁⛶ 40k/200k (20%)"},
+		},
+		{
+			name:     "abbreviates hostname first",
+			width:    45,
+			names:    []string{"user", "dir", "cost", "context"},
+			contents: []string{"me", "project", "1.2345", "⛁⛁⛶ 40k/200k (20%)"},
+		},
+		{
+			name:     "drops lowest priority before abbreviating others",
+			width:    38,
+			names:    []string{"dir", "cost", "context"},
+			contents: []string{"project", "1.2345", "⛁⛁⛶ 40k/200k (20%)"},
+		},
+		{
+			name:     "abbreviates next priority",
+			width:    35,
+			names:    []string{"dir", "cost", "context"},
+			contents: []string{"project", "1.23", "⛁⛁⛶ 40k/200k (20%)"},
+		},
+		{
+			name:     "high priority keeps full content",
+			width:    31,
+			names:    []string{"dir", "context"},
+			contents: []string{"project", "⛁⛁⛶ 40k/200k (20%)"},
+		},
+		{
+			name:     "keeps highest priority",
+			width:    16,
+			names:    []string{"context"},
+			contents: []string{"40k/200k (20%)"},
+		},
+		{
+			name:     "truncates last section",
+			width:    8,
+			names:    []string{"context"},
+			contents: []string{"40k/200…"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := newLine()
+			line.Fit(tt.width, nil)
+
+			assert.Equal(t, tt.names, names(line))
+			var contents []string
+			for _, section := range line.Sections {
+				contents = append(contents, section.Content)
+			}
+			assert.Equal(t, tt.contents, contents)
+			if last := line.Sections[len(line.Sections)-1]; last.Content != "⛁⛁⛶ 40k/200k (20%)" {
+				assert.Nil(t, last.Spans, "spans are dropped with the content they describe")
+			}
+			if tt.width > 0 {
+				assert.LessOrEqual(t, line.Width(), tt.width)
+			}
+		})
+	}
+}
+
+func TestStatusLineFitEqualPriorityDropsRightmost(t *testing.T) {
+	line := &StatusLine{
+		Separator: " ",
+		Sections: []Section{
+			{Name: "a", Content: "aaaa"},
+			{Name: "b", Content: "bbbb"},
+			{Name: "c", Content: "cccc"},
+		},
+	}
+
+	line.Fit(9, nil)
+	assert.Len(t, line.Sections, 2)
+	assert.Equal(t, "a", line.Sections[0].Name)
+	assert.Equal(t, "b", line.Sections[1].Name)
+}
+
+func TestTerminalWidth(t *testing.T) {
+	t.Setenv("COLUMNS", "120")
+	assert.Equal(t, 80, TerminalWidth(80))
+	assert.Equal(t, 120, TerminalWidth(0))
+
+	t.Setenv("COLUMNS", "wide")
+	assert.Equal(t, 0, TerminalWidth(0))
+}
+
+func TestStatusLineFitRightGroup(t *testing.T) {
+	line := &StatusLine{
+		Separator: " | ",
+		Sections:  []Section{{Name: "dir", Content: "project", Priority: 60}},
+		Right: []Section{
+			{Name: "user", Content: "me@host", Priority: 10},
+			{Name: "model", Content: "Opus", Priority: 80},
+		},
+	}
+
+	line.Fit(20, nil)
+	assert.Equal(t, 20, line.Columns)
+	require.Len(t, line.Sections, 1)
+	require.Len(t, line.Right, 1)
+	assert.Equal(t, "model", line.Right[0].Name)
+	assert.Equal(t, "project"+strings.Repeat(" ", 9)+"Opus", line.String())
+}
+
+func TestStatusLineFitPowerline(t *testing.T) {
+	sections := []Section{
+		{Name: "user", Content: "me@host", Short: "me", Priority: 10},
+		{Name: "dir", Content: "project", Priority: 60},
+		{Name: "model", Content: "Opus", Color: color.New(color.FgGreen), Priority: 80},
+		{Name: "context", Content: "⛁⛁⛁⛀⛶ 75%", Short: "75%", Priority: 90},
+	}
+
+	for _, format := range []string{FormatPowerline, FormatPowerlineRounded, FormatPowerlineASCII} {
+		for _, width := range []int{56, 30, 20, 8} {
+			t.Run(fmt.Sprintf("%s/%d", format, width), func(t *testing.T) {
+				line := &StatusLine{Sections: slices.Clone(sections)}
+				measure := GetWidthFunc(format)
+				line.Fit(width, measure)
+
+				render, err := GetRenderer(format)
+				require.NoError(t, err)
+				var buf bytes.Buffer
+				require.NoError(t, render(&buf, line))
+				drawn := VisibleWidth(strings.TrimSuffix(buf.String(), "\n"))
+				assert.Equal(t, measure(line), drawn)
+				assert.LessOrEqual(t, drawn, width)
+			})
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package statusline
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/fatih/color"
+
+	"github.com/bjulian5/claudestatusline/render"
+)
+
+// Context bar styles.
+const (
+	BarBlocks  = "blocks"
+	BarEighths = "eighths"
+	BarBraille = "braille"
+	BarASCII   = "ascii"
+)
+
+const maxBarWidth = 100
+
+// barStyle describes how a progress bar is drawn. Levels are the glyphs for a
+// cell from empty to full; a cell is drawn with the highest level it has
+// completely reached. Open and Close, if set, enclose the bar.
+type barStyle struct {
+	Levels []string
+	Open   string
+	Close  string
+}
+
+var barStyles = map[string]barStyle{
+	BarBlocks:  {Levels: []string{"⛶", "⛀", "⛁"}},
+	BarEighths: {Levels: []string{"░", "▏", "▎", "▍", "▌", "▋", "▊", "▉", "█"}},
+	BarBraille: {Levels: []string{"⣀", "⣄", "⣤", "⣦", "⣶", "⣷", "⣿"}},
+	BarASCII:   {Levels: []string{"-", "#"}, Open: "[", Close: "]"},
+}
+
+// gradientStops are the colors a gradient bar passes through, placed at 0%,
+// ThresholdWarn and ThresholdCrit.
+var gradientStops = []struct {
+	percentage float64
+	rgb        [3]int
+}{
+	{0, [3]int{0x5f, 0xd7, 0x00}},
+	{ThresholdWarn, [3]int{0xff, 0xd7, 0x00}},
+	{ThresholdCrit, [3]int{0xff, 0x00, 0x00}},
+}
+
+// ProgressBar draws a percentage as a row of cells.
+type ProgressBar struct {
+	Style    string
+	Width    int
+	Gradient bool
+}
+
+// Spans draws the bar for percentage. With Gradient set each filled cell is
+// colored by its position in the bar; otherwise the spans are uncolored.
+func (p ProgressBar) Spans(percentage float64) []render.Span {
+	style, ok := barStyles[p.Style]
+	if !ok {
+		style = barStyles[BarBlocks]
+	}
+	width := p.Width
+	if width <= 0 {
+		width = BlocksFull
+	}
+
+	var spans []render.Span
+	if style.Open != "" {
+		spans = append(spans, render.Span{Text: style.Open})
+	}
+	filled := percentage / 100 * float64(width)
+	top := len(style.Levels) - 1
+	for i := range width {
+		fraction := min(max(filled-float64(i), 0), 1)
+		level := int(math.Floor(fraction * float64(top)))
+
+		span := render.Span{Text: style.Levels[level]}
+		if p.Gradient && level > 0 {
+			span.Color = gradientColor((float64(i) + 0.5) / float64(width) * 100)
+		}
+		if n := len(spans); n > 0 && spans[n-1].Color == nil && span.Color == nil {
+			spans[n-1].Text += span.Text
+		} else {
+			spans = append(spans, span)
+		}
+	}
+	if style.Close != "" {
+		spans = append(spans, render.Span{Text: style.Close})
+	}
+	return spans
+}
+
+// gradientColor interpolates between the gradient stops.
+func gradientColor(percentage float64) *color.Color {
+	last := gradientStops[len(gradientStops)-1]
+	if percentage >= last.percentage {
+		return color.RGB(last.rgb[0], last.rgb[1], last.rgb[2])
+	}
+
+	for i := 1; i < len(gradientStops); i++ {
+		from, to := gradientStops[i-1], gradientStops[i]
+		if percentage < to.percentage {
+			t := (percentage - from.percentage) / (to.percentage - from.percentage)
+			var rgb [3]int
+			for j := range rgb {
+				rgb[j] = from.rgb[j] + int(math.Round(t*float64(to.rgb[j]-from.rgb[j])))
+			}
+			return color.RGB(rgb[0], rgb[1], rgb[2])
+		}
+	}
+	return color.RGB(last.rgb[0], last.rgb[1], last.rgb[2])
+}
+
+func validateBarStyle(style string) error {
+	if _, ok := barStyles[style]; style != "" && !ok {
+		return fmt.Errorf("unknown bar style %q", style)
+	}
+	return nil
+}
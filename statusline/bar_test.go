@@ -0,0 +1,72 @@
+package statusline
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/claudestatusline/render"
+)
+
+func barText(spans []render.Span) string {
+	var b strings.Builder
+	for _, span := range spans {
+		b.WriteString(span.Text)
+	}
+	return b.String()
+}
+
+func TestProgressBarSpans(t *testing.T) {
+	tests := []struct {
+		name       string
+		bar        ProgressBar
+		percentage float64
+		expected   string
+	}{
+		{name: "default is blocks", bar: ProgressBar{}, percentage: 55, expected: "⛁⛁⛁⛁⛁⛀⛶⛶⛶⛶"},
+		{name: "blocks below half a cell", bar: ProgressBar{Style: BarBlocks}, percentage: 54, expected: "⛁⛁⛁⛁⛁⛶⛶⛶⛶⛶"},
+		{name: "blocks full", bar: ProgressBar{Style: BarBlocks}, percentage: 100, expected: "⛁⛁⛁⛁⛁⛁⛁⛁⛁⛁"},
+		{name: "over full", bar: ProgressBar{Style: BarBlocks, Width: 4}, percentage: 130, expected: "⛁⛁⛁⛁"},
+		{name: "eighths", bar: ProgressBar{Style: BarEighths, Width: 4}, percentage: 40, expected: "█▌░░"},
+		{name: "braille", bar: ProgressBar{Style: BarBraille, Width: 4}, percentage: 62.5, expected: "⣿⣿⣦⣀"},
+		{name: "ascii", bar: ProgressBar{Style: BarASCII, Width: 8}, percentage: 50, expected: "[####----]"},
+		{name: "ascii rounds down", bar: ProgressBar{Style: BarASCII, Width: 8}, percentage: 60, expected: "[####----]"},
+		{name: "wide", bar: ProgressBar{Style: BarASCII, Width: 20}, percentage: 25, expected: "[#####---------------]"},
+		{name: "unknown style falls back to blocks", bar: ProgressBar{Style: "wave", Width: 2}, percentage: 50, expected: "⛁⛶"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spans := tt.bar.Spans(tt.percentage)
+			assert.Equal(t, tt.expected, barText(spans))
+			for _, span := range spans {
+				assert.Nil(t, span.Color, "bars without a gradient are uncolored")
+			}
+		})
+	}
+}
+
+func TestProgressBarGradient(t *testing.T) {
+	bar := ProgressBar{Style: BarASCII, Width: 10, Gradient: true}
+	spans := bar.Spans(90)
+	assert.Equal(t, "[#########-]", barText(spans))
+
+	require.Len(t, spans, 12, "open, nine colored cells and the uncolored rest")
+	assert.Nil(t, spans[0].Color)
+	assert.Equal(t, color.RGB(0x5f, 0xd7, 0x00), gradientColor(0))
+	assert.Equal(t, gradientColor(5), spans[1].Color)
+	assert.Equal(t, gradientColor(85), spans[9].Color)
+	assert.Equal(t, "-]", spans[10].Text+spans[11].Text)
+}
+
+func TestGradientColor(t *testing.T) {
+	assert.Equal(t, color.RGB(0x5f, 0xd7, 0x00), gradientColor(0))
+	assert.Equal(t, color.RGB(0xaf, 0xd7, 0x00), gradientColor(ThresholdWarn/2))
+	assert.Equal(t, color.RGB(0xff, 0xd7, 0x00), gradientColor(ThresholdWarn))
+	assert.Equal(t, color.RGB(0xff, 0x6b, 0x00), gradientColor((ThresholdWarn+ThresholdCrit)/2))
+	assert.Equal(t, color.RGB(0xff, 0x00, 0x00), gradientColor(ThresholdCrit))
+	assert.Equal(t, color.RGB(0xff, 0x00, 0x00), gradientColor(150))
+}
@@ -0,0 +1,311 @@
+package statusline
+
+import (
+	"cmp"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/bjulian5/claudestatusline/filecache"
+	"github.com/bjulian5/claudestatusline/render"
+	"github.com/bjulian5/claudestatusline/transcript"
+)
+
+const (
+	// BlockDuration is the length of a usage window; a new window starts with
+	// the first message after the previous one expires.
+	BlockDuration = 5 * time.Hour
+
+	DefaultBlockCacheTTL = 30 * time.Second
+
+	// blockLookback bounds how far back transcripts are read when finding
+	// where the current block started.
+	blockLookback = 24 * time.Hour
+)
+
+// UsageRecord is a single billed assistant response. Its cost is the one
+// recorded in the transcript when present, otherwise it is computed from the
+// model pricing table.
+type UsageRecord struct {
+	Timestamp time.Time `json:"timestamp"`
+	Tokens    int       `json:"tokens"`
+	CostUSD   float64   `json:"cost_usd"`
+	ID        string    `json:"id,omitempty"`
+}
+
+// transcriptRecords is what has been read from one transcript so far:
+// records newer than the lookback, and where to continue reading.
+type transcriptRecords struct {
+	Cursor  transcript.Cursor `json:"cursor"`
+	Records []UsageRecord     `json:"records"`
+}
+
+// UsageBlock aggregates the usage in one 5-hour window.
+type UsageBlock struct {
+	Start        time.Time `json:"start"`
+	LastActivity time.Time `json:"last_activity"`
+	Tokens       int       `json:"tokens"`
+	CostUSD      float64   `json:"cost_usd"`
+}
+
+func (b *UsageBlock) End() time.Time {
+	return b.Start.Add(BlockDuration)
+}
+
+func (b *UsageBlock) IsActive(now time.Time) bool {
+	return now.Before(b.End()) && now.Sub(b.LastActivity) < BlockDuration
+}
+
+// GroupUsageBlocks splits records into 5-hour blocks. A block starts at the
+// hour of its first record and ends 5 hours later; a record after the end, or
+// after a gap of 5 hours, starts the next block.
+func GroupUsageBlocks(records []UsageRecord) []UsageBlock {
+	records = slices.Clone(records)
+	slices.SortFunc(records, func(a, b UsageRecord) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+
+	var blocks []UsageBlock
+	for _, record := range records {
+		if n := len(blocks); n > 0 {
+			current := &blocks[n-1]
+			if record.Timestamp.Before(current.End()) && record.Timestamp.Sub(current.LastActivity) < BlockDuration {
+				current.LastActivity = record.Timestamp
+				current.Tokens += record.Tokens
+				current.CostUSD += record.CostUSD
+				continue
+			}
+		}
+
+		blocks = append(blocks, UsageBlock{
+			Start:        record.Timestamp.Truncate(time.Hour),
+			LastActivity: record.Timestamp,
+			Tokens:       record.Tokens,
+			CostUSD:      record.CostUSD,
+		})
+	}
+	return blocks
+}
+
+// BlockScanner finds the active usage block from every transcript under the
+// Claude projects directory.
+type BlockScanner struct {
+	ProjectsDir string
+	Parser      *transcript.Parser
+	Cache       *filecache.Cache
+	CacheTTL    time.Duration
+	Now         func() time.Time
+}
+
+// DefaultProjectsDir returns the directory Claude Code stores transcripts in.
+func DefaultProjectsDir() (string, error) {
+	if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, "projects"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, ".claude", "projects"), nil
+}
+
+// CurrentBlock returns the active block, or nil if there has been no usage in
+// the last 5 hours. Scanning every transcript is expensive, so the result is
+// cached for CacheTTL.
+func (s *BlockScanner) CurrentBlock() (*UsageBlock, error) {
+	now := s.Now()
+	key := "usage-block:" + s.ProjectsDir
+
+	var cached UsageBlock
+	if age, found := s.Cache.Load(key, &cached); found && age < s.CacheTTL {
+		return activeBlock(&cached, now), nil
+	}
+
+	records, err := s.readRecords(now.Add(-blockLookback))
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := GroupUsageBlocks(records)
+	if len(blocks) == 0 {
+		_ = s.Cache.Store(key, UsageBlock{})
+		return nil, nil
+	}
+
+	latest := blocks[len(blocks)-1]
+	_ = s.Cache.Store(key, latest)
+	return activeBlock(&latest, now), nil
+}
+
+func activeBlock(block *UsageBlock, now time.Time) *UsageBlock {
+	if block.Start.IsZero() || !block.IsActive(now) {
+		return nil
+	}
+	return block
+}
+
+// readRecords collects usage records newer than since from all transcripts.
+// Resumed sessions copy earlier messages into the new transcript, so records
+// are deduplicated by message and request ID.
+func (s *BlockScanner) readRecords(since time.Time) ([]UsageRecord, error) {
+	var records []UsageRecord
+	seen := make(map[string]bool)
+
+	err := filepath.WalkDir(s.ProjectsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == s.ProjectsDir {
+				return err
+			}
+			return nil
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".jsonl") {
+			return nil
+		}
+		if info, err := d.Info(); err != nil || info.ModTime().Before(since) {
+			return nil
+		}
+
+		for _, record := range s.transcriptRecords(path, since) {
+			if record.ID != "" {
+				if seen[record.ID] {
+					continue
+				}
+				seen[record.ID] = true
+			}
+			records = append(records, record)
+		}
+		return nil
+	})
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to scan transcripts: %w", err)
+	}
+	return records, nil
+}
+
+// transcriptRecords returns the records newer than since in one transcript.
+// The records read so far are cached with the transcript's cursor, so only
+// lines appended since the last scan are parsed, and a scan cut short by the
+// deadline still saves the transcripts it finished.
+func (s *BlockScanner) transcriptRecords(path string, since time.Time) []UsageRecord {
+	key := "usage-records:" + path
+	var state transcriptRecords
+	s.Cache.Load(key, &state)
+
+	entries, reset, err := s.Parser.ReadAssistantEntriesFrom(path, &state.Cursor)
+	if err != nil {
+		return nil
+	}
+	if reset {
+		state.Records = nil
+	}
+
+	models := s.Parser.Models
+	if models == nil {
+		models = transcript.NewModelRegistry(nil)
+	}
+	for _, entry := range entries {
+		timestamp, err := time.Parse(time.RFC3339Nano, entry.Timestamp)
+		if err != nil {
+			continue
+		}
+
+		cost := entry.CostUSD
+		if cost == 0 {
+			breakdown := models.Cost(entry.Message.Model, entry.Message.Usage)
+			cost = breakdown.Total()
+		}
+		state.Records = append(state.Records, UsageRecord{
+			Timestamp: timestamp,
+			Tokens:    entry.Message.Usage.Total(),
+			CostUSD:   cost,
+			ID:        entry.DedupKey(),
+		})
+	}
+
+	count := len(state.Records)
+	state.Records = slices.DeleteFunc(state.Records, func(record UsageRecord) bool {
+		return record.Timestamp.Before(since)
+	})
+	if len(entries) > 0 || reset || len(state.Records) != count {
+		_ = s.Cache.Store(key, state)
+	}
+	return state.Records
+}
+
+// BlockInfo is what the usage block section displays.
+type BlockInfo struct {
+	Block *UsageBlock
+	Now   time.Time
+}
+
+func (b *BlockInfo) Remaining() time.Duration {
+	return max(b.Block.End().Sub(b.Now), 0)
+}
+
+// ProjectedCost extrapolates the block's cost to its end at the rate seen
+// since the block started.
+func (b *BlockInfo) ProjectedCost() float64 {
+	elapsed := b.Now.Sub(b.Block.Start)
+	if elapsed <= 0 {
+		return b.Block.CostUSD
+	}
+	return b.Block.CostUSD / elapsed.Hours() * BlockDuration.Hours()
+}
+
+func (b *BlockInfo) ToSection() render.Section {
+	return render.Section{
+		Content: fmt.Sprintf("%s tok $%.2f · %s left → $%.2f",
+			formatTokenCount(b.Block.Tokens), b.Block.CostUSD,
+			formatDuration(b.Remaining()), b.ProjectedCost()),
+		Short: fmt.Sprintf("$%.2f · %s left", b.Block.CostUSD, formatDuration(b.Remaining())),
+		Color: color.New(color.FgBlue),
+		Values: map[string]any{
+			"start":              b.Block.Start,
+			"tokens":             b.Block.Tokens,
+			"cost_usd":           b.Block.CostUSD,
+			"remaining_seconds":  int(b.Remaining().Seconds()),
+			"projected_cost_usd": b.ProjectedCost(),
+		},
+	}
+}
+
+// formatDuration renders a duration as hours and minutes, e.g. "2h13m".
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+	if hours == 0 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh%02dm", hours, minutes)
+}
+
+func newBlockScanner(cfg BlocksConfig, models *transcript.ModelRegistry) (*BlockScanner, error) {
+	projectsDir := cfg.ProjectsDir
+	if projectsDir == "" {
+		dir, err := DefaultProjectsDir()
+		if err != nil {
+			return nil, err
+		}
+		projectsDir = dir
+	}
+
+	parser := transcript.NewParser()
+	parser.Models = models
+	return &BlockScanner{
+		ProjectsDir: projectsDir,
+		Parser:      parser,
+		Cache:       filecache.New(),
+		CacheTTL:    cmp.Or(time.Duration(cfg.CacheTTL), DefaultBlockCacheTTL),
+		Now:         time.Now,
+	}, nil
+}
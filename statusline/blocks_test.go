@@ -0,0 +1,241 @@
+package statusline
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/claudestatusline/filecache"
+	"github.com/bjulian5/claudestatusline/transcript"
+)
+
+func TestGroupUsageBlocks(t *testing.T) {
+	base := time.Date(2025, 3, 12, 9, 20, 0, 0, time.UTC)
+	at := func(offset time.Duration) time.Time { return base.Add(offset) }
+
+	records := []UsageRecord{
+		{Timestamp: at(2 * time.Hour), Tokens: 200, CostUSD: 2},
+		{Timestamp: at(0), Tokens: 100, CostUSD: 1},
+		// 09:00 + 5h = 14:00, so 14:30 starts a new block.
+		{Timestamp: at(5*time.Hour + 10*time.Minute), Tokens: 300, CostUSD: 3},
+		// A gap of more than 5 hours also starts a new block.
+		{Timestamp: at(11 * time.Hour), Tokens: 400, CostUSD: 4},
+	}
+
+	blocks := GroupUsageBlocks(records)
+	require.Len(t, blocks, 3)
+
+	assert.Equal(t, time.Date(2025, 3, 12, 9, 0, 0, 0, time.UTC), blocks[0].Start)
+	assert.Equal(t, at(2*time.Hour), blocks[0].LastActivity)
+	assert.Equal(t, 300, blocks[0].Tokens)
+	assert.InDelta(t, 3.0, blocks[0].CostUSD, 1e-9)
+
+	assert.Equal(t, time.Date(2025, 3, 12, 14, 0, 0, 0, time.UTC), blocks[1].Start)
+	assert.Equal(t, 300, blocks[1].Tokens)
+
+	assert.Equal(t, time.Date(2025, 3, 12, 20, 0, 0, 0, time.UTC), blocks[2].Start)
+	assert.Equal(t, 400, blocks[2].Tokens)
+
+	assert.Empty(t, GroupUsageBlocks(nil))
+}
+
+func TestUsageBlockIsActive(t *testing.T) {
+	block := UsageBlock{
+		Start:        time.Date(2025, 3, 12, 9, 0, 0, 0, time.UTC),
+		LastActivity: time.Date(2025, 3, 12, 9, 30, 0, 0, time.UTC),
+	}
+
+	assert.True(t, block.IsActive(time.Date(2025, 3, 12, 13, 59, 0, 0, time.UTC)))
+	assert.False(t, block.IsActive(time.Date(2025, 3, 12, 14, 0, 0, 0, time.UTC)))
+}
+
+func TestBlockInfo(t *testing.T) {
+	info := &BlockInfo{
+		Block: &UsageBlock{
+			Start:   time.Date(2025, 3, 12, 9, 0, 0, 0, time.UTC),
+			Tokens:  1500000,
+			CostUSD: 4.0,
+		},
+		Now: time.Date(2025, 3, 12, 11, 0, 0, 0, time.UTC),
+	}
+
+	assert.Equal(t, 3*time.Hour, info.Remaining())
+	assert.InDelta(t, 10.0, info.ProjectedCost(), 1e-9)
+	assert.Equal(t, "1.5M tok $4.00 · 3h00m left → $10.00", info.ToSection().Content)
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		duration time.Duration
+		expected string
+	}{
+		{0, "0m"},
+		{45 * time.Minute, "45m"},
+		{2*time.Hour + 13*time.Minute + 20*time.Second, "2h13m"},
+		{5 * time.Hour, "5h00m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, formatDuration(tt.duration))
+		})
+	}
+}
+
+func assistantLine(timestamp time.Time, messageID string, inputTokens int, cost float64) string {
+	return fmt.Sprintf(`{"type":"assistant","timestamp":%q,"requestId":"req_%s","costUSD":%g,`+
+		`"message":{"id":%q,"role":"assistant","usage":{"input_tokens":%d,"output_tokens":0}}}`,
+		timestamp.Format(time.RFC3339Nano), messageID, cost, messageID, inputTokens)
+}
+
+func TestBlockScannerCurrentBlock(t *testing.T) {
+	now := time.Date(2025, 3, 12, 12, 0, 0, 0, time.UTC)
+	projectsDir := t.TempDir()
+
+	writeTranscript := func(name string, modTime time.Time, lines ...string) {
+		path := filepath.Join(projectsDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644))
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+
+	writeTranscript("project-a/session-1.jsonl", now,
+		`{"type":"user","message":{"role":"user"}}`,
+		assistantLine(now.Add(-2*time.Hour), "msg_1", 1000, 0.5),
+		assistantLine(now.Add(-1*time.Hour), "msg_2", 2000, 1.0),
+	)
+	// A resumed session repeats msg_2, which must only be counted once.
+	writeTranscript("project-b/session-2.jsonl", now,
+		assistantLine(now.Add(-1*time.Hour), "msg_2", 2000, 1.0),
+		assistantLine(now.Add(-30*time.Minute), "msg_3", 3000, 1.5),
+	)
+	// Files untouched for longer than the lookback are not read.
+	writeTranscript("project-c/old.jsonl", now.Add(-48*time.Hour),
+		assistantLine(now.Add(-90*time.Minute), "msg_old", 999999, 99),
+	)
+	writeTranscript("project-a/notes.txt", now, "not a transcript")
+
+	scanner := &BlockScanner{
+		ProjectsDir: projectsDir,
+		Parser:      transcript.NewParser(),
+		Cache:       &filecache.Cache{Dir: t.TempDir(), Now: func() time.Time { return now }},
+		CacheTTL:    time.Minute,
+		Now:         func() time.Time { return now },
+	}
+
+	block, err := scanner.CurrentBlock()
+	require.NoError(t, err)
+	require.NotNil(t, block)
+	assert.Equal(t, time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC), block.Start)
+	assert.Equal(t, 6000, block.Tokens)
+	assert.InDelta(t, 3.0, block.CostUSD, 1e-9)
+
+	// Within the TTL the cached block is used even if transcripts change.
+	writeTranscript("project-a/session-3.jsonl", now, assistantLine(now.Add(-time.Minute), "msg_4", 5000, 2))
+	block, err = scanner.CurrentBlock()
+	require.NoError(t, err)
+	assert.Equal(t, 6000, block.Tokens)
+
+	// Once the block has expired it is no longer reported.
+	now = now.Add(6 * time.Hour)
+	scanner.Cache = nil
+	block, err = scanner.CurrentBlock()
+	require.NoError(t, err)
+	assert.Nil(t, block)
+}
+
+func TestBlockScannerReadsAppendedLines(t *testing.T) {
+	now := time.Date(2025, 3, 12, 12, 0, 0, 0, time.UTC)
+	projectsDir := t.TempDir()
+	path := filepath.Join(projectsDir, "session.jsonl")
+	appendLines := func(lines ...string) {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		require.NoError(t, err)
+		_, err = f.WriteString(strings.Join(lines, "\n") + "\n")
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+		require.NoError(t, os.Chtimes(path, now, now))
+	}
+
+	scanner := &BlockScanner{
+		ProjectsDir: projectsDir,
+		Parser:      transcript.NewParser(),
+		Cache:       &filecache.Cache{Dir: t.TempDir(), Now: func() time.Time { return now }},
+		CacheTTL:    time.Minute,
+		Now:         func() time.Time { return now },
+	}
+	cached := func() transcriptRecords {
+		var state transcriptRecords
+		_, found := scanner.Cache.Load("usage-records:"+path, &state)
+		require.True(t, found)
+		return state
+	}
+
+	appendLines(
+		assistantLine(now.Add(-30*time.Hour), "msg_old", 9000, 9),
+		assistantLine(now.Add(-time.Hour), "msg_1", 1000, 0.5),
+	)
+	block, err := scanner.CurrentBlock()
+	require.NoError(t, err)
+	require.NotNil(t, block)
+	assert.Equal(t, 1000, block.Tokens)
+	assert.Len(t, cached().Records, 1, "records past the lookback are not kept")
+
+	now = now.Add(2 * time.Minute)
+	appendLines(assistantLine(now.Add(-time.Minute), "msg_2", 2000, 1))
+	block, err = scanner.CurrentBlock()
+	require.NoError(t, err)
+	require.NotNil(t, block)
+	assert.Equal(t, 3000, block.Tokens)
+	assert.InDelta(t, 1.5, block.CostUSD, 1e-9)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, info.Size(), cached().Cursor.Offset)
+	assert.Len(t, cached().Records, 2)
+
+	// A replaced transcript is read again from the start.
+	now = now.Add(2 * time.Minute)
+	require.NoError(t, os.Remove(path))
+	appendLines(assistantLine(now.Add(-time.Minute), "msg_3", 4000, 2))
+	block, err = scanner.CurrentBlock()
+	require.NoError(t, err)
+	require.NotNil(t, block)
+	assert.Equal(t, 4000, block.Tokens)
+}
+
+func TestBlockScannerComputesMissingCost(t *testing.T) {
+	now := time.Date(2025, 3, 12, 12, 0, 0, 0, time.UTC)
+	projectsDir := t.TempDir()
+	line := fmt.Sprintf(`{"type":"assistant","timestamp":%q,"message":{"id":"msg_1","model":"claude-sonnet-4-20250514","role":"assistant",`+
+		`"usage":{"input_tokens":1000000,"output_tokens":0}}}`, now.Add(-time.Hour).Format(time.RFC3339))
+	require.NoError(t, os.WriteFile(filepath.Join(projectsDir, "session.jsonl"), []byte(line+"\n"), 0644))
+
+	scanner := &BlockScanner{
+		ProjectsDir: projectsDir,
+		Parser:      transcript.NewParser(),
+		Now:         func() time.Time { return now },
+	}
+
+	block, err := scanner.CurrentBlock()
+	require.NoError(t, err)
+	require.NotNil(t, block)
+	assert.InDelta(t, 3.0, block.CostUSD, 1e-9)
+}
+
+func TestBlockScannerMissingProjectsDir(t *testing.T) {
+	scanner := &BlockScanner{
+		ProjectsDir: filepath.Join(t.TempDir(), "missing"),
+		Parser:      transcript.NewParser(),
+		Now:         time.Now,
+	}
+
+	block, err := scanner.CurrentBlock()
+	require.NoError(t, err)
+	assert.Nil(t, block)
+}
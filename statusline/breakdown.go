@@ -0,0 +1,81 @@
+package statusline
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/fatih/color"
+
+	"github.com/bjulian5/claudestatusline/render"
+	"github.com/bjulian5/claudestatusline/transcript"
+)
+
+const (
+	// DefaultCostTolerance is the relative difference between the computed
+	// and reported cost above which the breakdown shows a warning.
+	DefaultCostTolerance = 0.1
+
+	// minDivergenceCostUSD avoids warning about rounding noise while a
+	// session's cost is still tiny.
+	minDivergenceCostUSD = 0.01
+)
+
+// CostBreakdownInfo is what the cost breakdown section displays: the locally
+// computed cost compared with the cost Claude Code reports.
+type CostBreakdownInfo struct {
+	Breakdown   transcript.CostBreakdown
+	ReportedUSD float64
+	Tolerance   float64
+}
+
+// Divergence returns the relative difference between the computed and
+// reported totals.
+func (c *CostBreakdownInfo) Divergence() float64 {
+	if c.ReportedUSD == 0 {
+		return 0
+	}
+	return (c.Breakdown.Total() - c.ReportedUSD) / c.ReportedUSD
+}
+
+func (c *CostBreakdownInfo) IsDiverged() bool {
+	return c.ReportedUSD >= minDivergenceCostUSD && math.Abs(c.Divergence()) > c.Tolerance
+}
+
+func (c *CostBreakdownInfo) ToSection() render.Section {
+	b := c.Breakdown
+	content := fmt.Sprintf("in $%.2f out $%.2f cw $%.2f cr $%.2f",
+		b.Input, b.Output, b.CacheWrite, b.CacheRead)
+	if c.IsDiverged() {
+		content += fmt.Sprintf(" ⚠ %+.0f%% vs $%.2f", c.Divergence()*100, c.ReportedUSD)
+	}
+	if b.UnpricedTokens > 0 {
+		content += fmt.Sprintf(" (+%s unpriced tok)", formatTokenCount(b.UnpricedTokens))
+	}
+
+	return render.Section{
+		Content: content,
+		Short:   fmt.Sprintf("$%.2f", b.Total()),
+		Color:   c.getBreakdownColor(),
+		Level:   c.getBreakdownLevel(),
+		Values: map[string]any{
+			"input_usd":       b.Input,
+			"output_usd":      b.Output,
+			"cache_write_usd": b.CacheWrite,
+			"cache_read_usd":  b.CacheRead,
+			"total_usd":       b.Total(),
+			"reported_usd":    c.ReportedUSD,
+			"unpriced_tokens": b.UnpricedTokens,
+		},
+	}
+}
+
+func (c *CostBreakdownInfo) getBreakdownLevel() render.Level {
+	if c.IsDiverged() {
+		return render.LevelWarn
+	}
+	return render.LevelOK
+}
+
+func (c *CostBreakdownInfo) getBreakdownColor() *color.Color {
+	return c.getBreakdownLevel().Color()
+}
@@ -0,0 +1,61 @@
+package statusline
+
+import (
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bjulian5/claudestatusline/render"
+	"github.com/bjulian5/claudestatusline/transcript"
+)
+
+func TestCostBreakdownInfoToSection(t *testing.T) {
+	breakdown := transcript.CostBreakdown{Input: 0.12, Output: 0.5, CacheWrite: 0.3, CacheRead: 0.08}
+
+	tests := []struct {
+		name            string
+		info            CostBreakdownInfo
+		expectedContent string
+		expectedColor   *color.Color
+		expectedLevel   render.Level
+	}{
+		{
+			name:            "matches reported cost",
+			info:            CostBreakdownInfo{Breakdown: breakdown, ReportedUSD: 1.02, Tolerance: 0.1},
+			expectedContent: "in $0.12 out $0.50 cw $0.30 cr $0.08",
+			expectedColor:   color.New(color.FgGreen),
+			expectedLevel:   render.LevelOK,
+		},
+		{
+			name:            "diverges from reported cost",
+			info:            CostBreakdownInfo{Breakdown: breakdown, ReportedUSD: 2.0, Tolerance: 0.1},
+			expectedContent: "in $0.12 out $0.50 cw $0.30 cr $0.08 ⚠ -50% vs $2.00",
+			expectedColor:   color.New(color.FgYellow),
+			expectedLevel:   render.LevelWarn,
+		},
+		{
+			name:            "tiny reported cost is not compared",
+			info:            CostBreakdownInfo{Breakdown: transcript.CostBreakdown{Input: 0.004}, ReportedUSD: 0.001, Tolerance: 0.1},
+			expectedContent: "in $0.00 out $0.00 cw $0.00 cr $0.00",
+			expectedColor:   color.New(color.FgGreen),
+			expectedLevel:   render.LevelOK,
+		},
+		{
+			name:            "unpriced usage",
+			info:            CostBreakdownInfo{Breakdown: transcript.CostBreakdown{UnpricedTokens: 5000}, Tolerance: 0.1},
+			expectedContent: "in $0.00 out $0.00 cw $0.00 cr $0.00 (+5k unpriced tok)",
+			expectedColor:   color.New(color.FgGreen),
+			expectedLevel:   render.LevelOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			section := tt.info.ToSection()
+			assert.Equal(t, tt.expectedContent, section.Content)
+			assert.Equal(t, tt.expectedColor, section.Color)
+			assert.Equal(t, tt.expectedLevel, section.Level)
+		})
+	}
+}
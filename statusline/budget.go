@@ -0,0 +1,64 @@
+package statusline
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/bjulian5/claudestatusline/render"
+)
+
+// BudgetInfo compares spend across all sessions with the configured budgets.
+// A zero budget means no limit is set.
+type BudgetInfo struct {
+	Spend        Spend
+	DailyBudget  float64
+	WeeklyBudget float64
+}
+
+func (b *BudgetInfo) ToSection() render.Section {
+	parts := []string{
+		"today " + formatSpend(b.Spend.Today, b.DailyBudget),
+		"week " + formatSpend(b.Spend.Week, b.WeeklyBudget),
+	}
+	return render.Section{
+		Content: strings.Join(parts, " · "),
+		Short:   parts[0],
+		Color:   b.getBudgetColor(),
+		Level:   b.getBudgetLevel(),
+		Values: map[string]any{
+			"today_usd":         b.Spend.Today,
+			"week_usd":          b.Spend.Week,
+			"daily_budget_usd":  b.DailyBudget,
+			"weekly_budget_usd": b.WeeklyBudget,
+		},
+	}
+}
+
+// getPercentage returns the higher of the daily and weekly budget usage.
+func (b *BudgetInfo) getPercentage() float64 {
+	var percentage float64
+	if b.DailyBudget > 0 {
+		percentage = max(percentage, b.Spend.Today/b.DailyBudget*100)
+	}
+	if b.WeeklyBudget > 0 {
+		percentage = max(percentage, b.Spend.Week/b.WeeklyBudget*100)
+	}
+	return percentage
+}
+
+func (b *BudgetInfo) getBudgetLevel() render.Level {
+	return render.ThresholdLevel(b.getPercentage(), ThresholdWarn, ThresholdCrit)
+}
+
+func (b *BudgetInfo) getBudgetColor() *color.Color {
+	return b.getBudgetLevel().Color()
+}
+
+func formatSpend(spent, budget float64) string {
+	if budget > 0 {
+		return fmt.Sprintf("$%.2f/$%.0f", spent, budget)
+	}
+	return fmt.Sprintf("$%.2f", spent)
+}
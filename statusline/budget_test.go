@@ -0,0 +1,50 @@
+package statusline
+
+import (
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBudgetInfoToSection(t *testing.T) {
+	tests := []struct {
+		name            string
+		budget          BudgetInfo
+		expectedContent string
+		expectedColor   *color.Color
+	}{
+		{
+			name:            "no budgets",
+			budget:          BudgetInfo{Spend: Spend{Today: 1.5, Week: 10}},
+			expectedContent: "today $1.50 · week $10.00",
+			expectedColor:   color.New(color.FgGreen),
+		},
+		{
+			name:            "under budget",
+			budget:          BudgetInfo{Spend: Spend{Today: 5, Week: 20}, DailyBudget: 20, WeeklyBudget: 100},
+			expectedContent: "today $5.00/$20 · week $20.00/$100",
+			expectedColor:   color.New(color.FgGreen),
+		},
+		{
+			name:            "daily budget warning",
+			budget:          BudgetInfo{Spend: Spend{Today: 14, Week: 20}, DailyBudget: 20, WeeklyBudget: 100},
+			expectedContent: "today $14.00/$20 · week $20.00/$100",
+			expectedColor:   color.New(color.FgYellow),
+		},
+		{
+			name:            "weekly budget exceeded",
+			budget:          BudgetInfo{Spend: Spend{Today: 1, Week: 120}, WeeklyBudget: 100},
+			expectedContent: "today $1.00 · week $120.00/$100",
+			expectedColor:   color.New(color.FgRed),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			section := tt.budget.ToSection()
+			assert.Equal(t, tt.expectedContent, section.Content)
+			assert.Equal(t, tt.expectedColor, section.Color)
+		})
+	}
+}
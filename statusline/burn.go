@@ -0,0 +1,78 @@
+package statusline
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/bjulian5/claudestatusline/event"
+	"github.com/bjulian5/claudestatusline/render"
+)
+
+const (
+	DefaultBurnWarnPerHour = 5.0
+	DefaultBurnCritPerHour = 15.0
+
+	// minBurnDuration avoids wildly extrapolated rates in the first moments
+	// of a session.
+	minBurnDuration = time.Minute
+)
+
+// BurnRate projects session spend from the cost so far and the session's
+// wall clock duration.
+type BurnRate struct {
+	CostUSD     float64
+	Duration    time.Duration
+	WarnPerHour float64
+	CritPerHour float64
+}
+
+func NewBurnRate(cost event.Cost, cfg BurnConfig) *BurnRate {
+	return &BurnRate{
+		CostUSD:     cost.TotalCostUSD,
+		Duration:    time.Duration(cost.TotalDurationMS) * time.Millisecond,
+		WarnPerHour: cfg.warnPerHour(),
+		CritPerHour: cfg.critPerHour(),
+	}
+}
+
+// PerHour returns the average spend per hour so far.
+func (b *BurnRate) PerHour() float64 {
+	if b.Duration <= 0 {
+		return 0
+	}
+	return b.CostUSD / b.Duration.Hours()
+}
+
+// ProjectedNextHour returns the total session cost an hour from now if the
+// current rate continues.
+func (b *BurnRate) ProjectedNextHour() float64 {
+	return b.CostUSD + b.PerHour()
+}
+
+func (b *BurnRate) IsWarmingUp() bool {
+	return b.Duration < minBurnDuration
+}
+
+func (b *BurnRate) ToSection() render.Section {
+	return render.Section{
+		Content: fmt.Sprintf("$%.2f/h → $%.2f", b.PerHour(), b.ProjectedNextHour()),
+		Short:   fmt.Sprintf("$%.2f/h", b.PerHour()),
+		Color:   b.getBurnColor(),
+		Level:   b.getBurnLevel(),
+		Values: map[string]any{
+			"cost_usd":           b.CostUSD,
+			"per_hour_usd":       b.PerHour(),
+			"projected_cost_usd": b.ProjectedNextHour(),
+		},
+	}
+}
+
+func (b *BurnRate) getBurnLevel() render.Level {
+	return render.ThresholdLevel(b.PerHour(), b.WarnPerHour, b.CritPerHour)
+}
+
+func (b *BurnRate) getBurnColor() *color.Color {
+	return b.getBurnLevel().Color()
+}
@@ -0,0 +1,82 @@
+package statusline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bjulian5/claudestatusline/event"
+)
+
+func TestBurnRate(t *testing.T) {
+	tests := []struct {
+		name              string
+		cost              event.Cost
+		cfg               BurnConfig
+		expectedPerHour   float64
+		expectedProjected float64
+		expectedColor     *color.Color
+		expectedContent   string
+	}{
+		{
+			name:              "slow session is green",
+			cost:              event.Cost{TotalCostUSD: 1.0, TotalDurationMS: 30 * 60 * 1000},
+			expectedPerHour:   2.0,
+			expectedProjected: 3.0,
+			expectedColor:     color.New(color.FgGreen),
+			expectedContent:   "$2.00/h → $3.00",
+		},
+		{
+			name:              "default warn threshold",
+			cost:              event.Cost{TotalCostUSD: 3.0, TotalDurationMS: 20 * 60 * 1000},
+			expectedPerHour:   9.0,
+			expectedProjected: 12.0,
+			expectedColor:     color.New(color.FgYellow),
+			expectedContent:   "$9.00/h → $12.00",
+		},
+		{
+			name:              "default crit threshold",
+			cost:              event.Cost{TotalCostUSD: 10.0, TotalDurationMS: 30 * 60 * 1000},
+			expectedPerHour:   20.0,
+			expectedProjected: 30.0,
+			expectedColor:     color.New(color.FgRed),
+			expectedContent:   "$20.00/h → $30.00",
+		},
+		{
+			name:              "custom thresholds",
+			cost:              event.Cost{TotalCostUSD: 1.0, TotalDurationMS: 30 * 60 * 1000},
+			cfg:               BurnConfig{WarnPerHour: 1, CritPerHour: 1.5},
+			expectedPerHour:   2.0,
+			expectedProjected: 3.0,
+			expectedColor:     color.New(color.FgRed),
+			expectedContent:   "$2.00/h → $3.00",
+		},
+		{
+			name:              "zero duration",
+			cost:              event.Cost{TotalCostUSD: 1.0},
+			expectedPerHour:   0,
+			expectedProjected: 1.0,
+			expectedColor:     color.New(color.FgGreen),
+			expectedContent:   "$0.00/h → $1.00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			burn := NewBurnRate(tt.cost, tt.cfg)
+			assert.InDelta(t, tt.expectedPerHour, burn.PerHour(), 0.0001)
+			assert.InDelta(t, tt.expectedProjected, burn.ProjectedNextHour(), 0.0001)
+
+			section := burn.ToSection()
+			assert.Equal(t, tt.expectedContent, section.Content)
+			assert.Equal(t, tt.expectedColor, section.Color)
+		})
+	}
+}
+
+func TestBurnRateIsWarmingUp(t *testing.T) {
+	assert.True(t, NewBurnRate(event.Cost{TotalDurationMS: 30 * 1000}, BurnConfig{}).IsWarmingUp())
+	assert.False(t, NewBurnRate(event.Cost{TotalDurationMS: int64(2 * time.Minute / time.Millisecond)}, BurnConfig{}).IsWarmingUp())
+}
@@ -0,0 +1,141 @@
+package statusline
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/bjulian5/claudestatusline/event"
+	"github.com/bjulian5/claudestatusline/filecache"
+	"github.com/bjulian5/claudestatusline/render"
+)
+
+const (
+	DefaultCommandTimeout  = 500 * time.Millisecond
+	DefaultCommandCacheTTL = 30 * time.Second
+
+	// commandWaitDelay bounds how long a timed out command's children may
+	// keep its output open after the shell has been killed.
+	commandWaitDelay = 100 * time.Millisecond
+
+	// commandDeadlineMargin is how long before the render deadline a command
+	// is stopped, leaving time for its children to let go of its output and
+	// for the failure to be cached before the process exits.
+	commandDeadlineMargin = 2 * commandWaitDelay
+)
+
+// CommandConfig declares a section whose content is the first line printed
+// by a shell command. The command receives the event JSON on stdin and runs
+// in the workspace directory.
+type CommandConfig struct {
+	Command  string   `json:"command"`
+	Timeout  Duration `json:"timeout,omitempty"`
+	CacheTTL Duration `json:"cache_ttl,omitempty"`
+}
+
+func (c CommandConfig) timeout() time.Duration {
+	return cmp.Or(time.Duration(c.Timeout), DefaultCommandTimeout)
+}
+
+func (c CommandConfig) cacheTTL() time.Duration {
+	return cmp.Or(time.Duration(c.CacheTTL), DefaultCommandCacheTTL)
+}
+
+// cachedCommandOutput is a command's result as stored in the file cache. Err
+// is set when the command failed, so that a broken command is not retried on
+// every render.
+type cachedCommandOutput struct {
+	Output string `json:"output"`
+	Err    string `json:"err,omitempty"`
+}
+
+// CommandRunner runs command sections, reusing output younger than the
+// command's cache TTL.
+type CommandRunner struct {
+	Cache *filecache.Cache
+}
+
+// Output returns the first line the command printed for event. When the
+// command fails or times out the previous output, however old, is kept for
+// another TTL rather than running a slow command on every render. The
+// command is stopped before ctx's deadline so that this holds even for
+// commands slower than the status line may take.
+func (r *CommandRunner) Output(ctx context.Context, name string, command CommandConfig, event *event.StatusHookEvent) (string, error) {
+	key := "command:" + name + ":" + command.Command + ":" + event.Workspace.CurrentDir
+
+	var cached cachedCommandOutput
+	age, found := r.Cache.Load(key, &cached)
+	if !found || age >= command.cacheTTL() {
+		output, err := runCommand(ctx, command, event)
+		switch {
+		case err == nil:
+			cached = cachedCommandOutput{Output: output}
+		case !found || cached.Err != "":
+			cached = cachedCommandOutput{Err: err.Error()}
+		}
+		_ = r.Cache.Store(key, cached)
+	}
+
+	if cached.Err != "" {
+		return "", fmt.Errorf("command %s: %s", name, cached.Err)
+	}
+	return cached.Output, nil
+}
+
+func runCommand(ctx context.Context, command CommandConfig, event *event.StatusHookEvent) (string, error) {
+	input, err := json.Marshal(event)
+	if err != nil {
+		return "", err
+	}
+
+	timeout := command.timeout()
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = min(timeout, time.Until(deadline)-commandDeadlineMargin)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command.Command)
+	cmd.Dir = event.Workspace.CurrentDir
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.WaitDelay = commandWaitDelay
+
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("timed out after %s", timeout.Round(time.Millisecond))
+		}
+		return "", err
+	}
+	line, _, _ := strings.Cut(string(output), "\n")
+	return strings.TrimSpace(line), nil
+}
+
+// commandProvider builds the section declared by the named command. A
+// command that prints nothing leaves the section out; one that fails is
+// marked as an error.
+type commandProvider struct {
+	name    string
+	command CommandConfig
+}
+
+func (p *commandProvider) Section(ctx context.Context, event *event.StatusHookEvent, cfg *Config) (*render.Section, error) {
+	runner := &CommandRunner{Cache: filecache.New()}
+	output, err := runner.Output(ctx, p.name, p.command, event)
+	if err != nil {
+		return nil, err
+	}
+	if output == "" {
+		return nil, nil
+	}
+
+	return &render.Section{
+		Content: output,
+		Values:  map[string]any{"output": output},
+	}, nil
+}
@@ -0,0 +1,132 @@
+package statusline
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/claudestatusline/event"
+	"github.com/bjulian5/claudestatusline/filecache"
+	"github.com/bjulian5/claudestatusline/render"
+)
+
+func TestRunCommand(t *testing.T) {
+	dir := t.TempDir()
+	event := &event.StatusHookEvent{SessionID: "abc", Workspace: event.Workspace{CurrentDir: dir}}
+
+	tests := []struct {
+		name     string
+		command  string
+		expected string
+		err      string
+	}{
+		{"first line", `printf 'PROJ-42\nmore\n'`, "PROJ-42", ""},
+		{"reads the event", `grep -o '"session_id":"[a-z]*"'`, `"session_id":"abc"`, ""},
+		{"runs in the workspace", `pwd`, dir, ""},
+		{"no output", `true`, "", ""},
+		{"failure", `exit 3`, "", "exit status 3"},
+		{"timeout", `sleep 5`, "", "timed out after 50ms"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command := CommandConfig{Command: tt.command, Timeout: Duration(50 * time.Millisecond)}
+			output, err := runCommand(context.Background(), command, event)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+		})
+	}
+}
+
+func TestCommandRunnerCache(t *testing.T) {
+	now := time.Now()
+	runner := &CommandRunner{Cache: &filecache.Cache{Dir: t.TempDir(), Now: func() time.Time { return now }}}
+	event := &event.StatusHookEvent{Workspace: event.Workspace{CurrentDir: t.TempDir()}}
+
+	counter := filepath.Join(t.TempDir(), "runs")
+	command := CommandConfig{
+		Command:  `echo run >> ` + counter + ` && cat ` + counter + ` | wc -l`,
+		CacheTTL: Duration(time.Minute),
+	}
+
+	output, err := runner.Output(context.Background(), "runs", command, event)
+	require.NoError(t, err)
+	assert.Equal(t, "1", output)
+
+	output, err = runner.Output(context.Background(), "runs", command, event)
+	require.NoError(t, err)
+	assert.Equal(t, "1", output, "output within the TTL is reused")
+
+	now = now.Add(2 * time.Minute)
+	output, err = runner.Output(context.Background(), "runs", command, event)
+	require.NoError(t, err)
+	assert.Equal(t, "2", output, "stale output is refreshed")
+
+	now = now.Add(2 * time.Minute)
+	require.NoError(t, os.Remove(counter))
+	require.NoError(t, os.Mkdir(counter, 0700))
+	output, err = runner.Output(context.Background(), "runs", command, event)
+	require.NoError(t, err)
+	assert.Equal(t, "2", output, "a failing command keeps the previous output")
+}
+
+func TestCommandRunnerFailure(t *testing.T) {
+	runner := &CommandRunner{Cache: &filecache.Cache{Dir: t.TempDir()}}
+	event := &event.StatusHookEvent{Workspace: event.Workspace{CurrentDir: t.TempDir()}}
+
+	_, err := runner.Output(context.Background(), "broken", CommandConfig{Command: "exit 1"}, event)
+	assert.ErrorContains(t, err, "command broken: exit status 1")
+
+	_, err = runner.Output(context.Background(), "broken", CommandConfig{Command: "exit 1"}, event)
+	assert.ErrorContains(t, err, "command broken", "the failure is cached")
+}
+
+func TestCommandRunnerDeadline(t *testing.T) {
+	runner := &CommandRunner{Cache: &filecache.Cache{Dir: t.TempDir()}}
+	event := &event.StatusHookEvent{Workspace: event.Workspace{CurrentDir: t.TempDir()}}
+	command := CommandConfig{Command: "sleep 3; echo SLOW", Timeout: Duration(5 * time.Second)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 400*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := runner.Output(ctx, "slow", command, event)
+	assert.ErrorContains(t, err, "command slow: timed out")
+	assert.Less(t, time.Since(start), 400*time.Millisecond, "the command is stopped before the deadline")
+
+	start = time.Now()
+	_, err = runner.Output(context.Background(), "slow", command, event)
+	assert.ErrorContains(t, err, "command slow: timed out", "the timeout is cached")
+	assert.Less(t, time.Since(start), 100*time.Millisecond, "the command is not run again")
+}
+
+func TestCommandSection(t *testing.T) {
+	useTempCache(t)
+	cfg := DefaultConfig()
+	cfg.Commands = map[string]CommandConfig{
+		"ticket": {Command: "echo PROJ-42"},
+		"silent": {Command: "true"},
+		"broken": {Command: "exit 1"},
+	}
+	cfg.Sections = []SectionConfig{{Name: "ticket", Color: "blue"}, {Name: "silent"}, {Name: "broken"}}
+	event := &event.StatusHookEvent{Workspace: event.Workspace{CurrentDir: t.TempDir()}}
+
+	line, err := NewStatusLineFromEvent(event, cfg)
+	require.NoError(t, err)
+	require.Len(t, line.Sections, 2, "a command without output is left out")
+	assert.Equal(t, "ticket", line.Sections[0].Name)
+	assert.Equal(t, "PROJ-42", line.Sections[0].Content)
+	assert.Equal(t, map[string]any{"output": "PROJ-42"}, line.Sections[0].Values)
+
+	assert.Equal(t, "broken", line.Sections[1].Name)
+	assert.Equal(t, render.LevelError, line.Sections[1].Level, "a failing command is marked as an error")
+	assert.Equal(t, map[string]any{"error": "command broken: exit status 1"}, line.Sections[1].Values)
+}
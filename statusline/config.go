@@ -0,0 +1,530 @@
+package statusline
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/bjulian5/claudestatusline/filecache"
+	"github.com/bjulian5/claudestatusline/git"
+	"github.com/bjulian5/claudestatusline/render"
+	"github.com/bjulian5/claudestatusline/transcript"
+)
+
+const (
+	configDirName     = "claudestatusline"
+	configFileName    = "config.json"
+	projectConfigName = ".claudestatusline.json"
+	configPathEnv     = "CLAUDESTATUSLINE_CONFIG"
+)
+
+const (
+	SectionUser      = "user"
+	SectionDirectory = "dir"
+	SectionGit       = "git"
+	SectionModel     = "model"
+	SectionCost      = "cost"
+	SectionContext   = "context"
+	SectionBurnRate  = "burn"
+	SectionBudget    = "budget"
+	SectionBlock     = "block"
+	SectionBreakdown = "cost_breakdown"
+)
+
+// maxPriority is the highest section priority; sections at maxPriority are
+// the last to be dropped when the status line does not fit.
+const maxPriority = 100
+
+// defaultPriorities orders sections for truncation: the host and secondary
+// cost figures go first, the model and context usage last.
+var defaultPriorities = map[string]int{
+	SectionUser:      10,
+	SectionBreakdown: 20,
+	SectionBlock:     30,
+	SectionBudget:    30,
+	SectionBurnRate:  40,
+	SectionCost:      50,
+	SectionDirectory: 60,
+	SectionGit:       70,
+	SectionModel:     80,
+	SectionContext:   90,
+}
+
+// Config controls which sections are rendered, in what order, and how they look.
+type Config struct {
+	Format    string                            `json:"format,omitempty"`
+	Color     string                            `json:"color,omitempty"`
+	Theme     string                            `json:"theme,omitempty"`
+	Themes    map[string]ThemeConfig            `json:"themes,omitempty"`
+	Separator string                            `json:"separator,omitempty"`
+	Sections  []SectionConfig                   `json:"sections,omitempty"`
+	Rows      []RowConfig                       `json:"rows,omitempty"`
+	Models    map[string]transcript.ModelConfig `json:"models,omitempty"`
+	Git       GitConfig                         `json:"git"`
+	BurnRate  BurnConfig                        `json:"burn_rate"`
+	Budget    BudgetConfig                      `json:"budget"`
+	Blocks    BlocksConfig                      `json:"blocks"`
+	Breakdown BreakdownConfig                   `json:"cost_breakdown"`
+	Context   ContextConfig                     `json:"context"`
+	Commands  map[string]CommandConfig          `json:"commands,omitempty"`
+	Deadline  Duration                          `json:"deadline,omitempty"`
+}
+
+// GitConfig controls the git section. Status and AheadBehind default to
+// enabled.
+type GitConfig struct {
+	Status        *bool    `json:"status,omitempty"`
+	AheadBehind   *bool    `json:"ahead_behind,omitempty"`
+	StatusTimeout Duration `json:"status_timeout,omitempty"`
+	CacheTTL      Duration `json:"cache_ttl,omitempty"`
+}
+
+// BurnConfig sets the $/hour rates at which the burn rate section turns
+// yellow and red.
+type BurnConfig struct {
+	WarnPerHour float64 `json:"warn_per_hour,omitempty"`
+	CritPerHour float64 `json:"crit_per_hour,omitempty"`
+}
+
+// BudgetConfig sets the daily and weekly spend limits in USD across all
+// sessions. Zero means no limit.
+type BudgetConfig struct {
+	Daily  float64 `json:"daily,omitempty"`
+	Weekly float64 `json:"weekly,omitempty"`
+}
+
+// BlocksConfig controls the 5-hour usage block section. ProjectsDir defaults
+// to the Claude Code projects directory.
+type BlocksConfig struct {
+	ProjectsDir string   `json:"projects_dir,omitempty"`
+	CacheTTL    Duration `json:"cache_ttl,omitempty"`
+}
+
+// BreakdownConfig sets how far the locally computed cost may drift from the
+// reported cost, as a fraction, before the breakdown section warns.
+type BreakdownConfig struct {
+	Tolerance float64 `json:"tolerance,omitempty"`
+}
+
+// ContextConfig controls the context usage bar. Bar is one of the bar styles
+// and defaults to blocks; Width defaults to BlocksFull cells.
+type ContextConfig struct {
+	Bar      string `json:"bar,omitempty"`
+	Width    int    `json:"width,omitempty"`
+	Gradient bool   `json:"gradient,omitempty"`
+}
+
+func (c ContextConfig) ProgressBar() ProgressBar {
+	return ProgressBar{Style: c.Bar, Width: c.Width, Gradient: c.Gradient}
+}
+
+// Duration is a time.Duration written in config as a string such as "300ms".
+type Duration time.Duration
+
+// RowConfig is one line of a multi-line layout. Sections are left aligned and
+// Right sections are right aligned when the width is known. Separator
+// defaults to the top level separator.
+type RowConfig struct {
+	Separator string          `json:"separator,omitempty"`
+	Sections  []SectionConfig `json:"sections,omitempty"`
+	Right     []SectionConfig `json:"right,omitempty"`
+}
+
+// SectionConfig configures a single section. Icon and Color override the
+// section's defaults when set; an empty Icon string removes the icon.
+type SectionConfig struct {
+	Name       string  `json:"name"`
+	Icon       *string `json:"icon,omitempty"`
+	Color      string  `json:"color,omitempty"`
+	Background string  `json:"background,omitempty"`
+	Priority   *int    `json:"priority,omitempty"`
+	Enabled    *bool   `json:"enabled,omitempty"`
+}
+
+func DefaultConfig() *Config {
+	return &Config{
+		Separator: render.DefaultSeparator,
+		Sections: []SectionConfig{
+			{Name: SectionUser},
+			{Name: SectionDirectory},
+			{Name: SectionGit},
+			{Name: SectionModel},
+			{Name: SectionCost},
+			{Name: SectionContext},
+		},
+	}
+}
+
+func (s SectionConfig) IsEnabled() bool {
+	return s.Enabled == nil || *s.Enabled
+}
+
+// priority returns the configured priority, or the section's default.
+func (s SectionConfig) priority() int {
+	if s.Priority != nil {
+		return *s.Priority
+	}
+	return defaultPriorities[s.Name]
+}
+
+// Apply overrides the section's icon and color with the configured values.
+func (s SectionConfig) Apply(section render.Section) render.Section {
+	if s.Icon != nil {
+		section.Icon = *s.Icon
+	}
+	if s.Color != "" {
+		if c, err := ParseColor(s.Color); err == nil {
+			section.Color = c
+		}
+	}
+	if s.Background != "" {
+		if c, err := ParseBackground(s.Background); err == nil {
+			section.Background = c
+		}
+	}
+	return section
+}
+
+// LoadConfig reads the user config followed by the project config found in
+// projectDir, with project settings taking precedence. Missing files are not
+// an error. If any file is invalid the default config is returned together
+// with the error so the caller can still render something useful.
+func LoadConfig(projectDir string) (*Config, error) {
+	cfg := DefaultConfig()
+	if path := userConfigPath(); path != "" {
+		if err := cfg.loadFile(path, false); err != nil {
+			return DefaultConfig(), err
+		}
+	}
+	if projectDir != "" {
+		if err := cfg.loadFile(filepath.Join(projectDir, projectConfigName), true); err != nil {
+			return DefaultConfig(), err
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return DefaultConfig(), err
+	}
+	return cfg, nil
+}
+
+func userConfigPath() string {
+	if path := os.Getenv(configPathEnv); path != "" {
+		return path
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, configDirName, configFileName)
+	}
+	return ""
+}
+
+// loadFile applies the config file at path on top of c. A project file comes
+// with whatever repository is checked out, so it may not declare commands:
+// that would let any repository run shell commands on every render.
+func (c *Config) loadFile(path string, project bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+
+	if project {
+		var keys map[string]json.RawMessage
+		if err := json.Unmarshal(data, &keys); err == nil {
+			if _, ok := keys["commands"]; ok {
+				return fmt.Errorf("invalid config %s: commands may only be declared in the user config", path)
+			}
+		}
+	}
+
+	// Lists in a later file replace the inherited list rather than being
+	// merged element by element. Sections and rows are alternative layouts,
+	// so setting either replaces both.
+	layer := *c
+	layer.Sections = nil
+	layer.Rows = nil
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&layer); err != nil {
+		return fmt.Errorf("invalid config %s: %w", path, err)
+	}
+
+	if layer.Sections == nil && layer.Rows == nil {
+		layer.Sections, layer.Rows = c.Sections, c.Rows
+	}
+	*c = layer
+	return nil
+}
+
+func (c *Config) Validate() error {
+	if IsTemplate(c.Format) {
+		if _, err := ParseTemplate(c.Format); err != nil {
+			return fmt.Errorf("format: %w", err)
+		}
+	} else if c.Format != "" {
+		if _, err := render.GetRenderer(c.Format); err != nil {
+			return fmt.Errorf("format: %w", err)
+		}
+	}
+	if err := render.ValidateColorSetting(c.Color); err != nil {
+		return fmt.Errorf("color: %w", err)
+	}
+	for name, theme := range c.Themes {
+		if _, err := NewTheme(theme); err != nil {
+			return fmt.Errorf("themes[%q]: %w", name, err)
+		}
+	}
+	if _, err := c.ResolveTheme(); err != nil {
+		return err
+	}
+
+	if len(c.Sections) > 0 && len(c.Rows) > 0 {
+		return fmt.Errorf("sections and rows cannot both be set")
+	}
+	for name, command := range c.Commands {
+		if _, ok := registeredProvider(name); ok || name == SectionConfigError {
+			return fmt.Errorf("commands[%q]: name is used by another section", name)
+		}
+		if strings.TrimSpace(command.Command) == "" {
+			return fmt.Errorf("commands[%q]: command must not be empty", name)
+		}
+		if command.Timeout != 0 && time.Duration(command.Timeout) >= c.deadline() {
+			return fmt.Errorf("commands[%q]: timeout must be shorter than the deadline (%s)", name, c.deadline())
+		}
+	}
+
+	if err := c.validateSections("sections", c.Sections); err != nil {
+		return err
+	}
+	for i, row := range c.Rows {
+		if err := c.validateSections(fmt.Sprintf("rows[%d].sections", i), row.Sections); err != nil {
+			return err
+		}
+		if err := c.validateSections(fmt.Sprintf("rows[%d].right", i), row.Right); err != nil {
+			return err
+		}
+	}
+
+	if warn, crit := c.BurnRate.warnPerHour(), c.BurnRate.critPerHour(); warn < 0 || crit < warn {
+		return fmt.Errorf("burn_rate: thresholds must satisfy 0 <= warn_per_hour (%g) <= crit_per_hour (%g)", warn, crit)
+	}
+
+	if err := validateBarStyle(c.Context.Bar); err != nil {
+		return fmt.Errorf("context: %w", err)
+	}
+	if c.Context.Width < 0 || c.Context.Width > maxBarWidth {
+		return fmt.Errorf("context: width must be between 1 and %d", maxBarWidth)
+	}
+
+	if c.Breakdown.Tolerance < 0 {
+		return fmt.Errorf("cost_breakdown: tolerance must not be negative")
+	}
+
+	if c.Budget.Daily < 0 || c.Budget.Weekly < 0 {
+		return fmt.Errorf("budget: limits must not be negative")
+	}
+
+	for id, model := range c.Models {
+		if model.ContextWindow < 0 {
+			return fmt.Errorf("models[%q]: context_window must not be negative", id)
+		}
+		if p := model.Pricing; p != nil && (p.Input < 0 || p.Output < 0 || p.CacheWrite < 0 || p.CacheRead < 0) {
+			return fmt.Errorf("models[%q]: pricing must not be negative", id)
+		}
+	}
+	return nil
+}
+
+func (c *Config) validateSections(path string, sections []SectionConfig) error {
+	for i, section := range sections {
+		if _, ok := c.sectionProvider(section.Name); !ok {
+			return fmt.Errorf("%s[%d]: unknown section %q", path, i, section.Name)
+		}
+		if section.Color != "" {
+			if _, err := ParseColor(section.Color); err != nil {
+				return fmt.Errorf("%s[%d]: %w", path, i, err)
+			}
+		}
+		if section.Priority != nil && (*section.Priority < 0 || *section.Priority > maxPriority) {
+			return fmt.Errorf("%s[%d]: priority must be between 0 and %d", path, i, maxPriority)
+		}
+		if section.Background != "" {
+			if _, err := ParseBackground(section.Background); err != nil {
+				return fmt.Errorf("%s[%d]: background: %w", path, i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// sectionProvider looks up a built-in or registered section, or one declared
+// under commands.
+func (c *Config) sectionProvider(name string) (SectionProvider, bool) {
+	if provider, ok := registeredProvider(name); ok {
+		return provider, true
+	}
+	if command, ok := c.Commands[name]; ok {
+		return &commandProvider{name: name, command: command}, true
+	}
+	return nil, false
+}
+
+// ResolveTheme returns the selected theme. Themes defined in the config take
+// precedence over built-in themes of the same name.
+func (c *Config) ResolveTheme() (Theme, error) {
+	name := cmp.Or(c.Theme, DefaultThemeName)
+	theme, ok := c.Themes[name]
+	if !ok {
+		if theme, ok = builtinThemes[name]; !ok {
+			return nil, fmt.Errorf("unknown theme %q", name)
+		}
+	}
+	return NewTheme(theme)
+}
+
+// Layout returns the configured rows, or a single row of Sections when no
+// rows are configured.
+func (c *Config) Layout() []RowConfig {
+	if len(c.Rows) > 0 {
+		return c.Rows
+	}
+	return []RowConfig{{Separator: c.Separator, Sections: c.Sections}}
+}
+
+func (g GitConfig) Options() git.Options {
+	return git.Options{
+		Status:        g.Status == nil || *g.Status,
+		AheadBehind:   g.AheadBehind == nil || *g.AheadBehind,
+		StatusTimeout: cmp.Or(time.Duration(g.StatusTimeout), git.DefaultStatusTimeout),
+		CacheTTL:      cmp.Or(time.Duration(g.CacheTTL), git.DefaultStatusCacheTTL),
+		Cache:         filecache.New(),
+	}
+}
+
+func (b BurnConfig) warnPerHour() float64 {
+	return cmp.Or(b.WarnPerHour, DefaultBurnWarnPerHour)
+}
+
+func (b BurnConfig) critPerHour() float64 {
+	return cmp.Or(b.CritPerHour, DefaultBurnCritPerHour)
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"500ms\": %w", err)
+	}
+
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	if parsed < 0 {
+		return fmt.Errorf("duration %q must not be negative", s)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// ModelRegistry returns the built-in model table extended with the
+// configured overrides.
+func (c *Config) ModelRegistry() *transcript.ModelRegistry {
+	return transcript.NewModelRegistry(c.Models)
+}
+
+var colorAttributes = map[string]color.Attribute{
+	"bold":       color.Bold,
+	"faint":      color.Faint,
+	"italic":     color.Italic,
+	"underline":  color.Underline,
+	"black":      color.FgBlack,
+	"red":        color.FgRed,
+	"green":      color.FgGreen,
+	"yellow":     color.FgYellow,
+	"blue":       color.FgBlue,
+	"magenta":    color.FgMagenta,
+	"cyan":       color.FgCyan,
+	"white":      color.FgWhite,
+	"hi-black":   color.FgHiBlack,
+	"hi-red":     color.FgHiRed,
+	"hi-green":   color.FgHiGreen,
+	"hi-yellow":  color.FgHiYellow,
+	"hi-blue":    color.FgHiBlue,
+	"hi-magenta": color.FgHiMagenta,
+	"hi-cyan":    color.FgHiCyan,
+	"hi-white":   color.FgHiWhite,
+}
+
+// ParseColor converts a space separated list of color and attribute names,
+// e.g. "bold cyan", into a color. Hex colors such as "#ff8700" select a
+// 24-bit foreground color.
+func ParseColor(spec string) (*color.Color, error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty color")
+	}
+
+	c := color.New()
+	for _, name := range fields {
+		if rgb, ok := parseHexColor(name); ok {
+			c.AddRGB(rgb[0], rgb[1], rgb[2])
+			continue
+		}
+		attr, ok := colorAttributes[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown color %q", name)
+		}
+		c.Add(attr)
+	}
+	return c, nil
+}
+
+// ParseBackground converts a single color name, e.g. "hi-blue", or a hex
+// color into a background color.
+func ParseBackground(spec string) (*color.Color, error) {
+	name := strings.ToLower(strings.TrimSpace(spec))
+	if rgb, ok := parseHexColor(name); ok {
+		return color.BgRGB(rgb[0], rgb[1], rgb[2]), nil
+	}
+	attr, ok := colorAttributes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown color %q", spec)
+	}
+	if !(attr >= color.FgBlack && attr <= color.FgWhite) && !(attr >= color.FgHiBlack && attr <= color.FgHiWhite) {
+		return nil, fmt.Errorf("%q is not a color", spec)
+	}
+	// Background colors are the foreground codes offset by 10.
+	return color.New(attr + 10), nil
+}
+
+// parseHexColor parses a "#rrggbb" color.
+func parseHexColor(s string) ([3]int, bool) {
+	var rgb [3]int
+	if len(s) != 7 || s[0] != '#' {
+		return rgb, false
+	}
+	for i := range rgb {
+		value, err := strconv.ParseUint(s[1+2*i:3+2*i], 16, 8)
+		if err != nil {
+			return rgb, false
+		}
+		rgb[i] = int(value)
+	}
+	return rgb, true
+}
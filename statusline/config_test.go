@@ -0,0 +1,446 @@
+package statusline
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/claudestatusline/git"
+	"github.com/bjulian5/claudestatusline/render"
+)
+
+func writeConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("missing files fall back to defaults", func(t *testing.T) {
+		t.Setenv(configPathEnv, filepath.Join(t.TempDir(), "missing.json"))
+
+		cfg, err := LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, DefaultConfig(), cfg)
+	})
+
+	t.Run("user config replaces sections", func(t *testing.T) {
+		userPath := filepath.Join(t.TempDir(), "config.json")
+		writeConfigFile(t, userPath, `{
+			"separator": " :: ",
+			"sections": [
+				{"name": "model", "icon": "", "color": "bold cyan"},
+				{"name": "context"}
+			]
+		}`)
+		t.Setenv(configPathEnv, userPath)
+
+		cfg, err := LoadConfig("")
+		require.NoError(t, err)
+		assert.Equal(t, " :: ", cfg.Separator)
+		require.Len(t, cfg.Sections, 2)
+		assert.Equal(t, SectionModel, cfg.Sections[0].Name)
+		require.NotNil(t, cfg.Sections[0].Icon)
+		assert.Empty(t, *cfg.Sections[0].Icon)
+		assert.Equal(t, "bold cyan", cfg.Sections[0].Color)
+		assert.Equal(t, SectionContext, cfg.Sections[1].Name)
+		assert.Nil(t, cfg.Sections[1].Icon)
+	})
+
+	t.Run("project config overrides user config", func(t *testing.T) {
+		userPath := filepath.Join(t.TempDir(), "config.json")
+		writeConfigFile(t, userPath, `{"separator": " :: ", "sections": [{"name": "user"}, {"name": "dir"}]}`)
+		t.Setenv(configPathEnv, userPath)
+
+		projectDir := t.TempDir()
+		writeConfigFile(t, filepath.Join(projectDir, projectConfigName), `{"sections": [{"name": "git", "enabled": false}]}`)
+
+		cfg, err := LoadConfig(projectDir)
+		require.NoError(t, err)
+		assert.Equal(t, " :: ", cfg.Separator, "separator should be inherited from the user config")
+		require.Len(t, cfg.Sections, 1)
+		assert.Equal(t, SectionGit, cfg.Sections[0].Name)
+		assert.False(t, cfg.Sections[0].IsEnabled())
+	})
+
+	t.Run("invalid json returns defaults and error", func(t *testing.T) {
+		userPath := filepath.Join(t.TempDir(), "config.json")
+		writeConfigFile(t, userPath, `{"sections": [`)
+		t.Setenv(configPathEnv, userPath)
+
+		cfg, err := LoadConfig("")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid config")
+		assert.Equal(t, DefaultConfig(), cfg)
+	})
+
+	t.Run("unknown field returns error", func(t *testing.T) {
+		userPath := filepath.Join(t.TempDir(), "config.json")
+		writeConfigFile(t, userPath, `{"seperator": " :: "}`)
+		t.Setenv(configPathEnv, userPath)
+
+		cfg, err := LoadConfig("")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "seperator")
+		assert.Equal(t, DefaultConfig(), cfg)
+	})
+
+	t.Run("unknown section returns error", func(t *testing.T) {
+		userPath := filepath.Join(t.TempDir(), "config.json")
+		writeConfigFile(t, userPath, `{"sections": [{"name": "weather"}]}`)
+		t.Setenv(configPathEnv, userPath)
+
+		cfg, err := LoadConfig("")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown section "weather"`)
+		assert.Equal(t, DefaultConfig(), cfg)
+	})
+
+	t.Run("unknown color returns error", func(t *testing.T) {
+		userPath := filepath.Join(t.TempDir(), "config.json")
+		writeConfigFile(t, userPath, `{"sections": [{"name": "dir", "color": "chartreuse"}]}`)
+		t.Setenv(configPathEnv, userPath)
+
+		_, err := LoadConfig("")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown color "chartreuse"`)
+	})
+}
+
+func TestLoadConfigRows(t *testing.T) {
+	userPath := filepath.Join(t.TempDir(), "config.json")
+	writeConfigFile(t, userPath, `{
+		"separator": " :: ",
+		"rows": [
+			{"sections": [{"name": "dir"}, {"name": "git"}], "right": [{"name": "user"}]},
+			{"separator": " · ", "sections": [{"name": "model"}, {"name": "context"}]}
+		]
+	}`)
+	t.Setenv(configPathEnv, userPath)
+
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Sections, "rows should replace the default sections")
+
+	layout := cfg.Layout()
+	require.Len(t, layout, 2)
+	assert.Equal(t, SectionDirectory, layout[0].Sections[0].Name)
+	assert.Equal(t, SectionUser, layout[0].Right[0].Name)
+	assert.Equal(t, " · ", layout[1].Separator)
+
+	projectDir := t.TempDir()
+	writeConfigFile(t, filepath.Join(projectDir, projectConfigName), `{"sections": [{"name": "model"}]}`)
+	cfg, err = LoadConfig(projectDir)
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Rows, "sections in a later file should replace inherited rows")
+	require.Len(t, cfg.Layout(), 1)
+	assert.Equal(t, " :: ", cfg.Layout()[0].Separator)
+
+	writeConfigFile(t, userPath, `{"rows": [{"right": [{"name": "weather"}]}]}`)
+	_, err = LoadConfig("")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `rows[0].right[0]: unknown section "weather"`)
+
+	writeConfigFile(t, userPath, `{"sections": [{"name": "dir"}], "rows": [{"sections": [{"name": "git"}]}]}`)
+	_, err = LoadConfig("")
+	assert.Error(t, err)
+}
+
+func TestLoadConfigModels(t *testing.T) {
+	userPath := filepath.Join(t.TempDir(), "config.json")
+	writeConfigFile(t, userPath, `{"models": {"claude-next": {"context_window": 500000}}}`)
+	t.Setenv(configPathEnv, userPath)
+
+	projectDir := t.TempDir()
+	writeConfigFile(t, filepath.Join(projectDir, projectConfigName), `{"models": {"claude-other": {"context_window": 300000}}}`)
+
+	cfg, err := LoadConfig(projectDir)
+	require.NoError(t, err)
+	assert.Equal(t, 500000, cfg.ModelRegistry().MaxTokens("claude-next-20260101"))
+	assert.Equal(t, 300000, cfg.ModelRegistry().MaxTokens("claude-other"))
+	assert.Len(t, cfg.Sections, len(DefaultConfig().Sections))
+
+	writeConfigFile(t, userPath, `{"models": {"claude-next": {"context_window": -1}}}`)
+	_, err = LoadConfig("")
+	assert.Error(t, err)
+}
+
+func TestLoadConfigGit(t *testing.T) {
+	userPath := filepath.Join(t.TempDir(), "config.json")
+	writeConfigFile(t, userPath, `{"git": {"status_timeout": "1s", "cache_ttl": "30s"}}`)
+	t.Setenv(configPathEnv, userPath)
+
+	projectDir := t.TempDir()
+	writeConfigFile(t, filepath.Join(projectDir, projectConfigName), `{"git": {"status": false}}`)
+
+	cfg, err := LoadConfig(projectDir)
+	require.NoError(t, err)
+
+	opts := cfg.Git.Options()
+	assert.False(t, opts.Status)
+	assert.Equal(t, time.Second, opts.StatusTimeout)
+	assert.Equal(t, 30*time.Second, opts.CacheTTL)
+
+	defaults := DefaultConfig().Git.Options()
+	assert.True(t, defaults.Status)
+	assert.Equal(t, git.DefaultStatusTimeout, defaults.StatusTimeout)
+	assert.Equal(t, git.DefaultStatusCacheTTL, defaults.CacheTTL)
+
+	writeConfigFile(t, userPath, `{"git": {"status_timeout": 300}}`)
+	_, err = LoadConfig("")
+	assert.Error(t, err)
+
+	writeConfigFile(t, userPath, `{"git": {"status_timeout": "-1s"}}`)
+	_, err = LoadConfig("")
+	assert.Error(t, err)
+}
+
+func TestLoadConfigBurnRate(t *testing.T) {
+	userPath := filepath.Join(t.TempDir(), "config.json")
+	writeConfigFile(t, userPath, `{"sections": [{"name": "burn"}], "burn_rate": {"warn_per_hour": 2, "crit_per_hour": 4}}`)
+	t.Setenv(configPathEnv, userPath)
+
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, cfg.BurnRate.warnPerHour())
+	assert.Equal(t, 4.0, cfg.BurnRate.critPerHour())
+
+	writeConfigFile(t, userPath, `{"burn_rate": {"warn_per_hour": 20}}`)
+	_, err = LoadConfig("")
+	assert.Error(t, err, "warn above the default crit threshold is invalid")
+}
+
+func TestLoadConfigFormat(t *testing.T) {
+	userPath := filepath.Join(t.TempDir(), "config.json")
+	writeConfigFile(t, userPath, `{"format": "tmux"}`)
+	t.Setenv(configPathEnv, userPath)
+
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, render.FormatTmux, cfg.Format)
+
+	writeConfigFile(t, userPath, `{"format": "html"}`)
+	_, err = LoadConfig("")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown format "html"`)
+
+	writeConfigFile(t, userPath, `{"format": "{{.Model.DisplayName}}"}`)
+	cfg, err = LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, "{{.Model.DisplayName}}", cfg.Format)
+
+	writeConfigFile(t, userPath, `{"format": "{{.Model.DisplayName"}`)
+	_, err = LoadConfig("")
+	assert.ErrorContains(t, err, "format: template: format:1: unclosed action")
+}
+
+func TestLoadConfigColor(t *testing.T) {
+	userPath := filepath.Join(t.TempDir(), "config.json")
+	writeConfigFile(t, userPath, `{"color": "256"}`)
+	t.Setenv(configPathEnv, userPath)
+
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, render.ColorSetting256, cfg.Color)
+
+	writeConfigFile(t, userPath, `{"color": "rainbow"}`)
+	_, err = LoadConfig("")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown color setting "rainbow"`)
+}
+
+func TestLoadConfigTheme(t *testing.T) {
+	userPath := filepath.Join(t.TempDir(), "config.json")
+	writeConfigFile(t, userPath, `{
+		"theme": "mine",
+		"themes": {"mine": {"path": "#00ff00", "crit": "bold #ff0000"}}
+	}`)
+	t.Setenv(configPathEnv, userPath)
+
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+	theme, err := cfg.ResolveTheme()
+	require.NoError(t, err)
+	assert.Equal(t, color.RGB(0, 255, 0), theme[RolePath])
+	assert.NotContains(t, theme, RoleModel)
+
+	projectDir := t.TempDir()
+	writeConfigFile(t, filepath.Join(projectDir, projectConfigName), `{"theme": "gruvbox"}`)
+	cfg, err = LoadConfig(projectDir)
+	require.NoError(t, err)
+	theme, err = cfg.ResolveTheme()
+	require.NoError(t, err)
+	assert.Equal(t, color.RGB(0x83, 0xa5, 0x98), theme[RolePath])
+
+	defaultTheme, err := DefaultConfig().ResolveTheme()
+	require.NoError(t, err)
+	assert.Empty(t, defaultTheme)
+
+	writeConfigFile(t, userPath, `{"theme": "neon"}`)
+	_, err = LoadConfig("")
+	assert.ErrorContains(t, err, `unknown theme "neon"`)
+
+	writeConfigFile(t, userPath, `{"themes": {"mine": {"path": "teal"}}}`)
+	_, err = LoadConfig("")
+	assert.ErrorContains(t, err, `themes["mine"]`)
+}
+
+func TestLoadConfigContext(t *testing.T) {
+	userPath := filepath.Join(t.TempDir(), "config.json")
+	writeConfigFile(t, userPath, `{"context": {"bar": "eighths", "width": 20, "gradient": true}}`)
+	t.Setenv(configPathEnv, userPath)
+
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, ProgressBar{Style: BarEighths, Width: 20, Gradient: true}, cfg.Context.ProgressBar())
+	assert.Equal(t, ProgressBar{}, DefaultConfig().Context.ProgressBar())
+
+	writeConfigFile(t, userPath, `{"context": {"bar": "wave"}}`)
+	_, err = LoadConfig("")
+	assert.ErrorContains(t, err, `unknown bar style "wave"`)
+
+	writeConfigFile(t, userPath, `{"context": {"width": 500}}`)
+	_, err = LoadConfig("")
+	assert.ErrorContains(t, err, "width")
+}
+
+func TestLoadConfigCommands(t *testing.T) {
+	userPath := filepath.Join(t.TempDir(), "config.json")
+	writeConfigFile(t, userPath, `{
+		"commands": {"ticket": {"command": "jira-current", "timeout": "800ms", "cache_ttl": "5m"}},
+		"sections": [{"name": "dir"}, {"name": "ticket", "color": "blue"}]
+	}`)
+	t.Setenv(configPathEnv, userPath)
+
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, CommandConfig{
+		Command:  "jira-current",
+		Timeout:  Duration(800 * time.Millisecond),
+		CacheTTL: Duration(5 * time.Minute),
+	}, cfg.Commands["ticket"])
+
+	tests := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{"undeclared section", `{"sections": [{"name": "ticket"}]}`, `unknown section "ticket"`},
+		{"empty command", `{"commands": {"ticket": {"command": " "}}}`, `commands["ticket"]: command must not be empty`},
+		{"built-in name", `{"commands": {"git": {"command": "git branch"}}}`, `commands["git"]: name is used by another section`},
+		{"timeout past deadline", `{"commands": {"slow": {"command": "sleep 3", "timeout": "5s"}}}`, `commands["slow"]: timeout must be shorter than the deadline (1s)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeConfigFile(t, userPath, tt.config)
+			_, err := LoadConfig("")
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestLoadConfigProjectCommands(t *testing.T) {
+	userPath := filepath.Join(t.TempDir(), "config.json")
+	writeConfigFile(t, userPath, `{"commands": {"ticket": {"command": "jira-current"}}}`)
+	t.Setenv(configPathEnv, userPath)
+
+	projectDir := t.TempDir()
+	projectPath := filepath.Join(projectDir, projectConfigName)
+	writeConfigFile(t, projectPath, `{"sections": [{"name": "dir"}, {"name": "ticket"}]}`)
+	cfg, err := LoadConfig(projectDir)
+	require.NoError(t, err, "a project may place commands declared by the user")
+	assert.Equal(t, "jira-current", cfg.Commands["ticket"].Command)
+
+	writeConfigFile(t, projectPath, `{"commands": {"x": {"command": "touch pwned"}}, "sections": [{"name": "x"}]}`)
+	cfg, err = LoadConfig(projectDir)
+	assert.ErrorContains(t, err, "commands may only be declared in the user config")
+	assert.Empty(t, cfg.Commands, "the default config is used instead")
+}
+
+func TestSectionConfigPriority(t *testing.T) {
+	assert.Equal(t, 10, SectionConfig{Name: SectionUser}.priority())
+	assert.Equal(t, 90, SectionConfig{Name: SectionContext}.priority())
+
+	priority := 95
+	assert.Equal(t, 95, SectionConfig{Name: SectionUser, Priority: &priority}.priority())
+
+	userPath := filepath.Join(t.TempDir(), "config.json")
+	writeConfigFile(t, userPath, `{"sections": [{"name": "user", "priority": 101}]}`)
+	t.Setenv(configPathEnv, userPath)
+	_, err := LoadConfig("")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "priority")
+}
+
+func TestSectionConfigApply(t *testing.T) {
+	icon := "★"
+	enabled := false
+	sc := SectionConfig{Name: SectionDirectory, Icon: &icon, Color: "red", Enabled: &enabled}
+
+	section := sc.Apply(render.Section{Icon: "x", Content: "project", Color: color.New(color.FgCyan)})
+	assert.Equal(t, "★", section.Icon)
+	assert.Equal(t, "project", section.Content)
+	assert.Equal(t, color.New(color.FgRed), section.Color)
+	assert.False(t, sc.IsEnabled())
+
+	withBackground := SectionConfig{Name: SectionDirectory, Background: "blue"}.Apply(render.Section{Content: "project"})
+	assert.Equal(t, color.New(color.BgBlue), withBackground.Background)
+
+	unchanged := SectionConfig{Name: SectionDirectory}.Apply(render.Section{Icon: "x", Content: "project"})
+	assert.Equal(t, "x", unchanged.Icon)
+	assert.Nil(t, unchanged.Color)
+}
+
+func TestParseBackground(t *testing.T) {
+	c, err := ParseBackground("Blue")
+	require.NoError(t, err)
+	assert.Equal(t, color.New(color.BgBlue), c)
+
+	c, err = ParseBackground("hi-black")
+	require.NoError(t, err)
+	assert.Equal(t, color.New(color.BgHiBlack), c)
+
+	c, err = ParseBackground("#002b36")
+	require.NoError(t, err)
+	assert.Equal(t, color.BgRGB(0, 0x2b, 0x36), c)
+
+	_, err = ParseBackground("bold")
+	assert.Error(t, err)
+	_, err = ParseBackground("bold red")
+	assert.Error(t, err)
+}
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected *color.Color
+		wantErr  bool
+	}{
+		{spec: "green", expected: color.New(color.FgGreen)},
+		{spec: "bold hi-magenta", expected: color.New(color.Bold, color.FgHiMagenta)},
+		{spec: "Cyan", expected: color.New(color.FgCyan)},
+		{spec: "#ff8700", expected: color.RGB(255, 135, 0)},
+		{spec: "bold #FF8700", expected: color.New(color.Bold).AddRGB(255, 135, 0)},
+		{spec: "#ff870", wantErr: true},
+		{spec: "", wantErr: true},
+		{spec: "purple", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			result, err := ParseColor(tt.spec)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
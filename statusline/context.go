@@ -0,0 +1,78 @@
+package statusline
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/bjulian5/claudestatusline/render"
+	"github.com/bjulian5/claudestatusline/transcript"
+)
+
+const (
+	BlocksFull    = 10
+	ThresholdWarn = 60
+	ThresholdCrit = 80
+)
+
+// ContextInfo is what the context section displays: the usage read from the
+// transcript drawn with Bar.
+type ContextInfo struct {
+	transcript.ContextUsage
+	Bar ProgressBar
+}
+
+func (c *ContextInfo) ToSection() render.Section {
+	currentTokens := c.Tokens()
+	percentage := c.Percentage()
+
+	// Format token counts with k notation
+	currentK := formatTokenCount(currentTokens)
+	maxK := formatTokenCount(c.MaxTokenCount)
+
+	spans := append(c.Bar.Spans(percentage), render.Span{
+		Text: fmt.Sprintf(" %s/%s (%.0f%%) %s", currentK, maxK, percentage, c.Notes),
+	})
+	var content strings.Builder
+	for _, span := range spans {
+		content.WriteString(span.Text)
+	}
+
+	section := render.Section{
+		Content: content.String(),
+		Short:   fmt.Sprintf("%s/%s (%.0f%%)", currentK, maxK, percentage),
+		Color:   c.getContextColor(),
+		Level:   c.getContextLevel(),
+		Values: map[string]any{
+			"tokens":     currentTokens,
+			"max_tokens": c.MaxTokenCount,
+			"percentage": percentage,
+		},
+	}
+	if c.Bar.Gradient {
+		section.Spans = spans
+	}
+	return section
+}
+
+func (c *ContextInfo) getContextLevel() render.Level {
+	return render.ThresholdLevel(c.Percentage(), ThresholdWarn, ThresholdCrit)
+}
+
+func (c *ContextInfo) getContextColor() *color.Color {
+	return c.getContextLevel().Color()
+}
+
+// formatTokenCount abbreviates a token count, switching from k to M once it
+// would round to 1000k.
+func formatTokenCount(tokens int) string {
+	if tokens >= 999_500 {
+		millions := fmt.Sprintf("%.1f", float64(tokens)/1_000_000)
+		return strings.TrimSuffix(millions, ".0") + "M"
+	}
+	if tokens >= 1000 {
+		return fmt.Sprintf("%.0fk", float64(tokens)/1000)
+	}
+	return fmt.Sprintf("%d", tokens)
+}
@@ -1,10 +1,12 @@
-package main
+package statusline
 
 import (
 	"testing"
 
 	"github.com/fatih/color"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/bjulian5/claudestatusline/transcript"
 )
 
 func TestContextInfoToSection(t *testing.T) {
@@ -18,9 +20,11 @@ func TestContextInfoToSection(t *testing.T) {
 		{
 			name: "low usage green",
 			context: ContextInfo{
-				InputTokenCount:  10000,
-				OutputTokenCount: 5000,
-				MaxTokenCount:    200000,
+				ContextUsage: transcript.ContextUsage{
+					InputTokenCount:  10000,
+					OutputTokenCount: 5000,
+					MaxTokenCount:    200000,
+				},
 			},
 			expectedBlocks: 0,
 			expectedColor:  color.New(color.FgGreen),
@@ -29,9 +33,11 @@ func TestContextInfoToSection(t *testing.T) {
 		{
 			name: "medium usage yellow",
 			context: ContextInfo{
-				InputTokenCount:  100000,
-				OutputTokenCount: 40000,
-				MaxTokenCount:    200000,
+				ContextUsage: transcript.ContextUsage{
+					InputTokenCount:  100000,
+					OutputTokenCount: 40000,
+					MaxTokenCount:    200000,
+				},
 			},
 			expectedBlocks: 7,
 			expectedColor:  color.New(color.FgYellow),
@@ -40,9 +46,11 @@ func TestContextInfoToSection(t *testing.T) {
 		{
 			name: "high usage red",
 			context: ContextInfo{
-				InputTokenCount:  150000,
-				OutputTokenCount: 30000,
-				MaxTokenCount:    200000,
+				ContextUsage: transcript.ContextUsage{
+					InputTokenCount:  150000,
+					OutputTokenCount: 30000,
+					MaxTokenCount:    200000,
+				},
 			},
 			expectedBlocks: 9,
 			expectedColor:  color.New(color.FgRed),
@@ -51,10 +59,12 @@ func TestContextInfoToSection(t *testing.T) {
 		{
 			name: "with notes",
 			context: ContextInfo{
-				InputTokenCount:  5000,
-				OutputTokenCount: 2000,
-				MaxTokenCount:    200000,
-				Notes:            "cached",
+				ContextUsage: transcript.ContextUsage{
+					InputTokenCount:  5000,
+					OutputTokenCount: 2000,
+					MaxTokenCount:    200000,
+					Notes:            "cached",
+				},
 			},
 			expectedBlocks: 0,
 			expectedColor:  color.New(color.FgGreen),
@@ -63,7 +73,9 @@ func TestContextInfoToSection(t *testing.T) {
 		{
 			name: "zero tokens",
 			context: ContextInfo{
-				MaxTokenCount: 200000,
+				ContextUsage: transcript.ContextUsage{
+					MaxTokenCount: 200000,
+				},
 			},
 			expectedBlocks: 0,
 			expectedColor:  color.New(color.FgGreen),
@@ -97,36 +109,44 @@ func TestContextInfoGetPercentage(t *testing.T) {
 		{
 			name: "50 percent",
 			context: ContextInfo{
-				InputTokenCount:  50000,
-				OutputTokenCount: 50000,
-				MaxTokenCount:    200000,
+				ContextUsage: transcript.ContextUsage{
+					InputTokenCount:  50000,
+					OutputTokenCount: 50000,
+					MaxTokenCount:    200000,
+				},
 			},
 			expected: 50.0,
 		},
 		{
 			name: "zero percent",
 			context: ContextInfo{
-				InputTokenCount:  0,
-				OutputTokenCount: 0,
-				MaxTokenCount:    200000,
+				ContextUsage: transcript.ContextUsage{
+					InputTokenCount:  0,
+					OutputTokenCount: 0,
+					MaxTokenCount:    200000,
+				},
 			},
 			expected: 0.0,
 		},
 		{
 			name: "100 percent",
 			context: ContextInfo{
-				InputTokenCount:  150000,
-				OutputTokenCount: 50000,
-				MaxTokenCount:    200000,
+				ContextUsage: transcript.ContextUsage{
+					InputTokenCount:  150000,
+					OutputTokenCount: 50000,
+					MaxTokenCount:    200000,
+				},
 			},
 			expected: 100.0,
 		},
 		{
 			name: "zero max tokens",
 			context: ContextInfo{
-				InputTokenCount:  1000,
-				OutputTokenCount: 1000,
-				MaxTokenCount:    0,
+				ContextUsage: transcript.ContextUsage{
+					InputTokenCount:  1000,
+					OutputTokenCount: 1000,
+					MaxTokenCount:    0,
+				},
 			},
 			expected: 0.0,
 		},
@@ -134,7 +154,7 @@ func TestContextInfoGetPercentage(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := tt.context.getPercentage()
+			result := tt.context.Percentage()
 			assert.Equal(t, tt.expected, result)
 		})
 	}
@@ -149,27 +169,33 @@ func TestContextInfoGetContextColor(t *testing.T) {
 		{
 			name: "green under 60%",
 			context: ContextInfo{
-				InputTokenCount:  50000,
-				OutputTokenCount: 10000,
-				MaxTokenCount:    200000,
+				ContextUsage: transcript.ContextUsage{
+					InputTokenCount:  50000,
+					OutputTokenCount: 10000,
+					MaxTokenCount:    200000,
+				},
 			},
 			expected: color.New(color.FgGreen),
 		},
 		{
 			name: "yellow at 65%",
 			context: ContextInfo{
-				InputTokenCount:  100000,
-				OutputTokenCount: 30000,
-				MaxTokenCount:    200000,
+				ContextUsage: transcript.ContextUsage{
+					InputTokenCount:  100000,
+					OutputTokenCount: 30000,
+					MaxTokenCount:    200000,
+				},
 			},
 			expected: color.New(color.FgYellow),
 		},
 		{
 			name: "red at 85%",
 			context: ContextInfo{
-				InputTokenCount:  150000,
-				OutputTokenCount: 20000,
-				MaxTokenCount:    200000,
+				ContextUsage: transcript.ContextUsage{
+					InputTokenCount:  150000,
+					OutputTokenCount: 20000,
+					MaxTokenCount:    200000,
+				},
 			},
 			expected: color.New(color.FgRed),
 		},
@@ -194,6 +220,10 @@ func TestFormatTokenCount(t *testing.T) {
 		{1500, "2k"},
 		{150000, "150k"},
 		{200000, "200k"},
+		{999499, "999k"},
+		{999500, "1M"},
+		{1000000, "1M"},
+		{1200000, "1.2M"},
 	}
 
 	for _, tt := range tests {
@@ -206,9 +236,11 @@ func TestFormatTokenCount(t *testing.T) {
 
 func TestContextVisualization(t *testing.T) {
 	context := ContextInfo{
-		InputTokenCount:  50000,
-		OutputTokenCount: 50000,
-		MaxTokenCount:    200000,
+		ContextUsage: transcript.ContextUsage{
+			InputTokenCount:  50000,
+			OutputTokenCount: 50000,
+			MaxTokenCount:    200000,
+		},
 	}
 
 	section := context.ToSection()
@@ -216,3 +248,30 @@ func TestContextVisualization(t *testing.T) {
 	assert.Contains(t, section.Content, "⛁⛁⛁⛁⛁", "Should have 5 filled blocks for 50%")
 	assert.Contains(t, section.Content, "⛶⛶⛶⛶⛶", "Should have 5 empty blocks for 50%")
 }
+
+func TestContextInfoBarStyles(t *testing.T) {
+	context := ContextInfo{
+		ContextUsage: transcript.ContextUsage{
+			InputTokenCount: 100000,
+			MaxTokenCount:   200000,
+		},
+		Bar: ProgressBar{Style: BarASCII, Width: 20},
+	}
+
+	section := context.ToSection()
+	assert.Equal(t, "[##########----------] 100k/200k (50%) ", section.Content)
+	assert.Empty(t, section.Spans)
+
+	context.Bar.Gradient = true
+	section = context.ToSection()
+	assert.Equal(t, "[##########----------] 100k/200k (50%) ", section.Content, "content is the same with a gradient")
+	assert.NotEmpty(t, section.Spans)
+	assert.Equal(t, color.New(color.FgGreen), section.Color, "the section color is kept for the text")
+}
+
+func TestContextInfoExtendedContext(t *testing.T) {
+	context := ContextInfo{
+		ContextUsage: transcript.ContextUsage{InputTokenCount: 105000, MaxTokenCount: 1000000},
+	}
+	assert.Contains(t, context.ToSection().Content, "105k/1M (10%)")
+}
@@ -0,0 +1,72 @@
+package statusline
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	debugLogName = "debug.log"
+	debugLogEnv  = "CLAUDESTATUSLINE_DEBUG_LOG"
+
+	// maxDebugLogSize is the size past which the log is moved aside to
+	// debug.log.1 and started afresh, so it cannot grow without bound.
+	maxDebugLogSize = 1 << 20
+)
+
+// DebugLog records the full details of errors that the status line only
+// shows as a marker. A nil *DebugLog discards them.
+type DebugLog struct {
+	Path string
+	Now  func() time.Time
+}
+
+// NewDebugLog returns the log named by $CLAUDESTATUSLINE_DEBUG_LOG, or
+// debug.log in the user cache directory. It returns nil if neither is
+// available.
+func NewDebugLog() *DebugLog {
+	path := os.Getenv(debugLogEnv)
+	if path == "" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return nil
+		}
+		path = filepath.Join(dir, configDirName, debugLogName)
+	}
+	return &DebugLog{
+		Path: path,
+		Now:  time.Now,
+	}
+}
+
+// Log appends err, as reported by the part of the status line called name.
+// Failing to write the log is not worth reporting, so it is ignored.
+func (l *DebugLog) Log(name string, err error) {
+	if l == nil || err == nil {
+		return
+	}
+
+	if info, statErr := os.Stat(l.Path); statErr == nil && info.Size() >= maxDebugLogSize {
+		_ = os.Rename(l.Path, l.Path+".1")
+	}
+	if mkdirErr := os.MkdirAll(filepath.Dir(l.Path), 0700); mkdirErr != nil {
+		return
+	}
+	f, openErr := os.OpenFile(l.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if openErr != nil {
+		return
+	}
+	defer f.Close()
+
+	// A single write keeps lines from concurrent processes whole.
+	_, _ = fmt.Fprintf(f, "%s %s: %v\n", l.now().Format(time.RFC3339), name, err)
+}
+
+func (l *DebugLog) now() time.Time {
+	if l.Now == nil {
+		return time.Now()
+	}
+	return l.Now()
+}
@@ -0,0 +1,57 @@
+package statusline
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugLog(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	log := &DebugLog{
+		Path: filepath.Join(t.TempDir(), "logs", debugLogName),
+		Now:  func() time.Time { return now },
+	}
+
+	log.Log("git", errors.New("exit status 128"))
+	log.Log("context", nil)
+	log.Log("user", errors.New("failed to get hostname"))
+
+	data, err := os.ReadFile(log.Path)
+	require.NoError(t, err)
+	assert.Equal(t, "2025-06-01T12:00:00Z git: exit status 128\n2025-06-01T12:00:00Z user: failed to get hostname\n", string(data))
+
+	t.Run("rotates", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(log.Path, []byte(strings.Repeat("x", maxDebugLogSize)), 0600))
+		log.Log("git", errors.New("again"))
+
+		data, err := os.ReadFile(log.Path)
+		require.NoError(t, err)
+		assert.Equal(t, "2025-06-01T12:00:00Z git: again\n", string(data))
+		info, err := os.Stat(log.Path + ".1")
+		require.NoError(t, err)
+		assert.EqualValues(t, maxDebugLogSize, info.Size())
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		var log *DebugLog
+		assert.NotPanics(t, func() { log.Log("git", errors.New("discarded")) })
+	})
+}
+
+func TestNewDebugLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "debug.log")
+	t.Setenv(debugLogEnv, path)
+	assert.Equal(t, path, NewDebugLog().Path)
+
+	t.Setenv(debugLogEnv, "")
+	dir, err := os.UserCacheDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, configDirName, debugLogName), NewDebugLog().Path)
+}
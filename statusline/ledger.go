@@ -0,0 +1,208 @@
+package statusline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	ledgerFileName  = "ledger.json"
+	ledgerDateFmt   = "2006-01-02"
+	ledgerRetention = 35 * 24 * time.Hour
+	ledgerLockWait  = 200 * time.Millisecond
+	ledgerLockStale = 5 * time.Second
+)
+
+// Ledger records the cost of every session so spend can be totalled across
+// sessions. Each session's cost is cumulative, so only the increase since the
+// previous snapshot is attributed to the day it was observed.
+type Ledger struct {
+	Path string
+	Now  func() time.Time
+}
+
+// Spend is the total recorded across all sessions.
+type Spend struct {
+	Today float64
+	Week  float64
+}
+
+type ledgerData struct {
+	Sessions map[string]*ledgerSession `json:"sessions"`
+}
+
+type ledgerSession struct {
+	LastCostUSD float64            `json:"last_cost_usd"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+	Days        map[string]float64 `json:"days"`
+}
+
+// NewLedger returns a ledger in the user data directory.
+func NewLedger() (*Ledger, error) {
+	dir, err := userDataDir()
+	if err != nil {
+		return nil, err
+	}
+	return &Ledger{
+		Path: filepath.Join(dir, configDirName, ledgerFileName),
+		Now:  time.Now,
+	}, nil
+}
+
+// userDataDir follows the XDG base directory spec, defaulting to
+// ~/.local/share.
+func userDataDir() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find data directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "share"), nil
+}
+
+// Record stores a cost snapshot for sessionID and returns the updated totals.
+func (l *Ledger) Record(sessionID string, costUSD float64) (*Spend, error) {
+	if err := os.MkdirAll(filepath.Dir(l.Path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create ledger directory: %w", err)
+	}
+
+	unlock, err := l.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	data, err := l.read()
+	if err != nil {
+		return nil, err
+	}
+
+	now := l.Now()
+	session, ok := data.Sessions[sessionID]
+	if !ok {
+		session = &ledgerSession{Days: make(map[string]float64)}
+		data.Sessions[sessionID] = session
+	}
+
+	delta := costUSD - session.LastCostUSD
+	if delta < 0 {
+		// The session's counter restarted; everything it reports is new.
+		delta = costUSD
+	}
+	if delta > 0 {
+		session.Days[now.Format(ledgerDateFmt)] += delta
+	}
+	session.LastCostUSD = costUSD
+	session.UpdatedAt = now
+
+	data.prune(now)
+	if err := l.write(data); err != nil {
+		return nil, err
+	}
+	return data.spend(now), nil
+}
+
+func (l *Ledger) read() (*ledgerData, error) {
+	data := &ledgerData{Sessions: make(map[string]*ledgerSession)}
+	content, err := os.ReadFile(l.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return data, nil
+		}
+		return nil, fmt.Errorf("failed to read ledger: %w", err)
+	}
+
+	if err := json.Unmarshal(content, data); err != nil {
+		return nil, fmt.Errorf("failed to parse ledger %s: %w", l.Path, err)
+	}
+	if data.Sessions == nil {
+		data.Sessions = make(map[string]*ledgerSession)
+	}
+	for _, session := range data.Sessions {
+		if session.Days == nil {
+			session.Days = make(map[string]float64)
+		}
+	}
+	return data, nil
+}
+
+func (l *Ledger) write(data *ledgerData) error {
+	content, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	tmp := l.Path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0600); err != nil {
+		return fmt.Errorf("failed to write ledger: %w", err)
+	}
+	return os.Rename(tmp, l.Path)
+}
+
+// lock serialises read-modify-write cycles between concurrent status line
+// processes using an exclusive lock file. A lock older than ledgerLockStale
+// is assumed to belong to a process that died and is taken over.
+func (l *Ledger) lock() (func(), error) {
+	lockPath := l.Path + ".lock"
+	deadline := time.Now().Add(ledgerLockWait)
+	for {
+		file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			file.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to lock ledger: %w", err)
+		}
+
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > ledgerLockStale {
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for ledger lock %s", lockPath)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// prune drops days that can no longer count towards any total.
+func (d *ledgerData) prune(now time.Time) {
+	cutoff := now.Add(-ledgerRetention).Format(ledgerDateFmt)
+	for id, session := range d.Sessions {
+		for day := range session.Days {
+			if day < cutoff {
+				delete(session.Days, day)
+			}
+		}
+		if len(session.Days) == 0 && now.Sub(session.UpdatedAt) > ledgerRetention {
+			delete(d.Sessions, id)
+		}
+	}
+}
+
+// spend totals today and the current week, which starts on Monday.
+func (d *ledgerData) spend(now time.Time) *Spend {
+	today := now.Format(ledgerDateFmt)
+	daysSinceMonday := (int(now.Weekday()) + 6) % 7
+	weekStart := now.AddDate(0, 0, -daysSinceMonday).Format(ledgerDateFmt)
+
+	spend := &Spend{}
+	for _, session := range d.Sessions {
+		for day, cost := range session.Days {
+			if day == today {
+				spend.Today += cost
+			}
+			if day >= weekStart && day <= today {
+				spend.Week += cost
+			}
+		}
+	}
+	return spend
+}
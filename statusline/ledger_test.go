@@ -0,0 +1,130 @@
+package statusline
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLedger(t *testing.T, now *time.Time) *Ledger {
+	t.Helper()
+	return &Ledger{
+		Path: filepath.Join(t.TempDir(), "ledger.json"),
+		Now:  func() time.Time { return *now },
+	}
+}
+
+func TestLedgerRecord(t *testing.T) {
+	// Wednesday
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, time.Local)
+	ledger := newTestLedger(t, &now)
+
+	spend, err := ledger.Record("a", 1.5)
+	require.NoError(t, err)
+	assert.InDelta(t, 1.5, spend.Today, 1e-9)
+	assert.InDelta(t, 1.5, spend.Week, 1e-9)
+
+	spend, err = ledger.Record("b", 2.0)
+	require.NoError(t, err)
+	assert.InDelta(t, 3.5, spend.Today, 1e-9)
+
+	// Only the increase in a session's cumulative cost is added.
+	spend, err = ledger.Record("a", 2.5)
+	require.NoError(t, err)
+	assert.InDelta(t, 4.5, spend.Today, 1e-9)
+
+	spend, err = ledger.Record("a", 2.5)
+	require.NoError(t, err)
+	assert.InDelta(t, 4.5, spend.Today, 1e-9)
+
+	// Thursday: session a keeps going, yesterday counts towards the week.
+	now = now.AddDate(0, 0, 1)
+	spend, err = ledger.Record("a", 3.0)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.5, spend.Today, 1e-9)
+	assert.InDelta(t, 5.0, spend.Week, 1e-9)
+
+	// Next Monday starts a new week.
+	now = time.Date(2025, 3, 17, 9, 0, 0, 0, time.Local)
+	spend, err = ledger.Record("c", 1.0)
+	require.NoError(t, err)
+	assert.InDelta(t, 1.0, spend.Today, 1e-9)
+	assert.InDelta(t, 1.0, spend.Week, 1e-9)
+}
+
+func TestLedgerRecordCostReset(t *testing.T) {
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, time.Local)
+	ledger := newTestLedger(t, &now)
+
+	_, err := ledger.Record("a", 5.0)
+	require.NoError(t, err)
+
+	spend, err := ledger.Record("a", 1.0)
+	require.NoError(t, err)
+	assert.InDelta(t, 6.0, spend.Today, 1e-9)
+}
+
+func TestLedgerPrune(t *testing.T) {
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.Local)
+	ledger := newTestLedger(t, &now)
+
+	_, err := ledger.Record("old", 10.0)
+	require.NoError(t, err)
+
+	now = now.AddDate(0, 2, 0)
+	_, err = ledger.Record("new", 1.0)
+	require.NoError(t, err)
+
+	data, err := ledger.read()
+	require.NoError(t, err)
+	assert.NotContains(t, data.Sessions, "old")
+	assert.Contains(t, data.Sessions, "new")
+}
+
+func TestLedgerConcurrentRecords(t *testing.T) {
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, time.Local)
+	ledger := newTestLedger(t, &now)
+
+	var wg sync.WaitGroup
+	for _, id := range []string{"a", "b", "c", "d"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := ledger.Record(id, 1.0)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	data, err := ledger.read()
+	require.NoError(t, err)
+	assert.InDelta(t, 4.0, data.spend(now).Today, 1e-9)
+}
+
+func TestLedgerStaleLock(t *testing.T) {
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, time.Local)
+	ledger := newTestLedger(t, &now)
+
+	lockPath := ledger.Path + ".lock"
+	require.NoError(t, os.WriteFile(lockPath, nil, 0600))
+	stale := time.Now().Add(-time.Minute)
+	require.NoError(t, os.Chtimes(lockPath, stale, stale))
+
+	_, err := ledger.Record("a", 1.0)
+	require.NoError(t, err)
+	assert.NoFileExists(t, lockPath)
+}
+
+func TestLedgerCorrupt(t *testing.T) {
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, time.Local)
+	ledger := newTestLedger(t, &now)
+	require.NoError(t, os.WriteFile(ledger.Path, []byte("{"), 0600))
+
+	_, err := ledger.Record("a", 1.0)
+	assert.Error(t, err)
+}
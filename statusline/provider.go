@@ -0,0 +1,149 @@
+package statusline
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bjulian5/claudestatusline/event"
+	"github.com/bjulian5/claudestatusline/filecache"
+	"github.com/bjulian5/claudestatusline/render"
+)
+
+// DefaultDeadline is how long sections are given to build before the status
+// line is rendered without them.
+const DefaultDeadline = time.Second
+
+// SectionProvider produces a section from the event. A nil section with a nil
+// error means the section has nothing to show and is skipped; an error is
+// shown as a marker and written to the debug log. Providers run
+// concurrently; one still running when ctx is done is shown with its last
+// known value instead.
+type SectionProvider interface {
+	Section(ctx context.Context, event *event.StatusHookEvent, cfg *Config) (*render.Section, error)
+}
+
+// SectionProviderFunc adapts a function to a SectionProvider.
+type SectionProviderFunc func(ctx context.Context, event *event.StatusHookEvent, cfg *Config) (*render.Section, error)
+
+func (f SectionProviderFunc) Section(ctx context.Context, event *event.StatusHookEvent, cfg *Config) (*render.Section, error) {
+	return f(ctx, event, cfg)
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = make(map[string]SectionProvider)
+)
+
+// Register makes a provider available as a section called name, to be
+// placed in the layout like the built-in sections. It is meant to be called
+// before Main, typically from an init function, and panics if name is
+// already taken or provider is nil.
+func Register(name string, provider SectionProvider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	if provider == nil {
+		panic("statusline: Register provider is nil")
+	}
+	if _, ok := sectionProviders[name]; ok || name == SectionConfigError {
+		panic("statusline: Register called for built-in section " + name)
+	}
+	if _, ok := providers[name]; ok {
+		panic("statusline: Register called twice for section " + name)
+	}
+	providers[name] = provider
+}
+
+// registeredProvider returns a built-in or registered provider.
+func registeredProvider(name string) (SectionProvider, bool) {
+	if provider, ok := sectionProviders[name]; ok {
+		return provider, true
+	}
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	provider, ok := providers[name]
+	return provider, ok
+}
+
+// providerResult is what a provider produced. Done is false for providers
+// that missed the deadline.
+type providerResult struct {
+	Section *render.Section
+	Err     error
+	Done    bool
+}
+
+// runProviders runs every provider concurrently and collects their results
+// in order, waiting no longer than ctx allows.
+func runProviders(ctx context.Context, event *event.StatusHookEvent, cfg *Config, providers []SectionProvider) []providerResult {
+	type indexedResult struct {
+		index int
+		providerResult
+	}
+
+	// The channel is buffered so providers that finish after the deadline
+	// do not block forever.
+	finished := make(chan indexedResult, len(providers))
+	for i, provider := range providers {
+		go func() {
+			section, err := provider.Section(ctx, event, cfg)
+			finished <- indexedResult{i, providerResult{Section: section, Err: err, Done: true}}
+		}()
+	}
+
+	results := make([]providerResult, len(providers))
+	for range providers {
+		select {
+		case result := <-finished:
+			results[result.index] = result.providerResult
+		case <-ctx.Done():
+			return results
+		}
+	}
+	return results
+}
+
+// cachedSection is a section as stored in the file cache, with its colors
+// broken down so they survive the JSON encoding.
+type cachedSection struct {
+	render.Section
+	Short      string           `json:"short,omitempty"`
+	Style      render.TextStyle `json:"style"`
+	Background render.TextStyle `json:"background"`
+}
+
+// sectionCacheKey identifies the last value of a section for the session and
+// directory, which are what built-in sections depend on.
+func sectionCacheKey(name string, event *event.StatusHookEvent) string {
+	return fmt.Sprintf("section:%s:%s:%s", name, event.SessionID, event.Workspace.CurrentDir)
+}
+
+func storeSection(cache *filecache.Cache, key string, section render.Section) {
+	_ = cache.Store(key, cachedSection{
+		Section:    section,
+		Short:      section.Short,
+		Style:      render.TextStyleOf(section.Color),
+		Background: render.TextStyleOf(section.Background),
+	})
+}
+
+// fallbackSection is shown for a section that missed the deadline: its last
+// value if there is one, otherwise a placeholder.
+func fallbackSection(cache *filecache.Cache, key string) render.Section {
+	var cached cachedSection
+	if _, ok := cache.Load(key, &cached); !ok {
+		return render.Section{Content: render.Ellipsis}
+	}
+
+	section := cached.Section
+	section.Short = cached.Short
+	section.Color = cached.Style.Color()
+	section.Background = cached.Background.Color()
+	return section
+}
+
+func (c *Config) deadline() time.Duration {
+	return cmp.Or(time.Duration(c.Deadline), DefaultDeadline)
+}
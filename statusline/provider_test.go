@@ -0,0 +1,192 @@
+package statusline
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/claudestatusline/event"
+	"github.com/bjulian5/claudestatusline/filecache"
+	"github.com/bjulian5/claudestatusline/render"
+)
+
+func staticProvider(content string, delay time.Duration) SectionProvider {
+	return SectionProviderFunc(func(ctx context.Context, event *event.StatusHookEvent, cfg *Config) (*render.Section, error) {
+		select {
+		case <-time.After(delay):
+			return &render.Section{Content: content}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	})
+}
+
+// register registers provider for the duration of the test, replacing any
+// provider registered under name before.
+func register(t *testing.T, name string, provider SectionProvider) {
+	unregister := func() {
+		providersMu.Lock()
+		defer providersMu.Unlock()
+		delete(providers, name)
+	}
+	unregister()
+	Register(name, provider)
+	t.Cleanup(unregister)
+}
+
+// useTempCache points the user cache directory, which holds the section
+// cache and the debug log, at a directory removed after the test.
+func useTempCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+}
+
+func TestRegister(t *testing.T) {
+	useTempCache(t)
+	register(t, "test_ticket", staticProvider("PROJ-42", 0))
+
+	cfg := DefaultConfig()
+	cfg.Sections = []SectionConfig{{Name: "test_ticket", Color: "blue"}}
+	require.NoError(t, cfg.Validate(), "registered sections can be used in the layout")
+
+	line, err := NewStatusLineFromEvent(&event.StatusHookEvent{}, cfg)
+	require.NoError(t, err)
+	require.Len(t, line.Sections, 1)
+	assert.Equal(t, "test_ticket", line.Sections[0].Name)
+	assert.Equal(t, "PROJ-42", line.Sections[0].Content)
+
+	cfg.Commands = map[string]CommandConfig{"test_ticket": {Command: "echo"}}
+	assert.ErrorContains(t, cfg.Validate(), "name is used by another section")
+
+	assert.PanicsWithValue(t, "statusline: Register called twice for section test_ticket", func() {
+		Register("test_ticket", staticProvider("again", 0))
+	})
+	assert.Panics(t, func() { Register(SectionGit, staticProvider("git", 0)) })
+	assert.Panics(t, func() { Register("test_nil", nil) })
+}
+
+func TestRunProviders(t *testing.T) {
+	failing := SectionProviderFunc(func(ctx context.Context, event *event.StatusHookEvent, cfg *Config) (*render.Section, error) {
+		return nil, errors.New("broken")
+	})
+	providers := []SectionProvider{
+		staticProvider("slow", 30*time.Millisecond),
+		staticProvider("fast", 0),
+		failing,
+		staticProvider("stuck", time.Hour),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	results := runProviders(ctx, &event.StatusHookEvent{}, DefaultConfig(), providers)
+
+	assert.Less(t, time.Since(start), time.Second, "the deadline bounds the wait")
+	require.Len(t, results, 4)
+	assert.Equal(t, "slow", results[0].Section.Content, "results keep the provider order")
+	assert.Equal(t, "fast", results[1].Section.Content)
+	assert.EqualError(t, results[2].Err, "broken")
+	assert.True(t, results[2].Done)
+	assert.False(t, results[3].Done, "the stuck provider missed the deadline")
+}
+
+func TestFallbackSection(t *testing.T) {
+	cache := &filecache.Cache{Dir: t.TempDir()}
+	key := sectionCacheKey(SectionGit, &event.StatusHookEvent{SessionID: "abc"})
+
+	assert.Equal(t, render.Section{Content: render.Ellipsis}, fallbackSection(cache, key), "a placeholder without a cached value")
+
+	storeSection(cache, key, render.Section{
+		Icon:       "*",
+		Content:    "main ↑1",
+		Short:      "main",
+		Color:      color.New(color.FgMagenta),
+		Background: color.RGB(0x26, 0x8b, 0xd2),
+		Spans:      []render.Span{{Text: "main ↑1"}},
+		Level:      render.LevelWarn,
+		Values:     map[string]any{"branch": "main"},
+	})
+	section := fallbackSection(cache, key)
+	assert.Equal(t, "*", section.Icon)
+	assert.Equal(t, "main ↑1", section.Content)
+	assert.Equal(t, "main", section.Short)
+	assert.Equal(t, render.LevelWarn, section.Level)
+	assert.Equal(t, map[string]any{"branch": "main"}, section.Values)
+	assert.Equal(t, render.TextStyleOf(color.New(color.FgMagenta)), render.TextStyleOf(section.Color))
+	assert.Equal(t, render.TextStyleOf(color.RGB(0x26, 0x8b, 0xd2)), render.TextStyleOf(section.Background))
+	assert.Nil(t, section.Spans)
+}
+
+func TestBuildSectionsDeadline(t *testing.T) {
+	useTempCache(t)
+
+	register(t, "test_slow", staticProvider("done", time.Hour))
+
+	cfg := DefaultConfig()
+	cfg.Deadline = Duration(50 * time.Millisecond)
+	cfg.Sections = []SectionConfig{{Name: SectionModel}, {Name: "test_slow"}}
+	event := &event.StatusHookEvent{Model: event.Model{DisplayName: "Opus"}}
+
+	line, err := NewStatusLineFromEvent(event, cfg)
+	require.NoError(t, err)
+	require.Len(t, line.Sections, 2)
+	assert.Equal(t, "Opus", line.Sections[0].Content)
+	assert.Equal(t, "test_slow", line.Sections[1].Name)
+	assert.Equal(t, render.Ellipsis, line.Sections[1].Content, "a section without a previous value shows a placeholder")
+
+	storeSection(filecache.New(), sectionCacheKey("test_slow", event), render.Section{Content: "earlier"})
+	line, err = NewStatusLineFromEvent(event, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "earlier", line.Sections[1].Content, "the last value is shown while the section is slow")
+
+	register(t, "test_slow", staticProvider("done", 0))
+	line, err = NewStatusLineFromEvent(event, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "done", line.Sections[1].Content)
+}
+
+func TestBuildSectionsErrors(t *testing.T) {
+	useTempCache(t)
+	logPath := filepath.Join(t.TempDir(), "debug.log")
+	t.Setenv(debugLogEnv, logPath)
+
+	register(t, "test_broken", SectionProviderFunc(func(ctx context.Context, event *event.StatusHookEvent, cfg *Config) (*render.Section, error) {
+		return nil, errors.New("ticket service unavailable")
+	}))
+
+	icon, priority := "T", 7
+	cfg := DefaultConfig()
+	cfg.Sections = []SectionConfig{
+		{Name: SectionModel},
+		{Name: "test_broken", Icon: &icon, Color: "blue", Priority: &priority},
+		{Name: "test_missing"},
+	}
+	line, err := NewStatusLineFromEvent(&event.StatusHookEvent{Model: event.Model{DisplayName: "Opus"}}, cfg)
+	require.NoError(t, err, "a failing section does not fail the status line")
+	require.Len(t, line.Sections, 3)
+	assert.Equal(t, "Opus", line.Sections[0].Content)
+
+	broken := line.Sections[1]
+	assert.Equal(t, "test_broken", broken.Name)
+	assert.Equal(t, "⚠", broken.Icon, "the marker ignores the configured icon")
+	assert.Equal(t, "test_broken", broken.Content)
+	assert.Equal(t, render.LevelError, broken.Level)
+	assert.Equal(t, render.LevelError.Color(), broken.Color)
+	assert.Equal(t, 7, broken.Priority)
+	assert.Equal(t, map[string]any{"error": "ticket service unavailable"}, broken.Values)
+
+	assert.Equal(t, "test_missing", line.Sections[2].Content)
+	assert.Equal(t, render.LevelError, line.Sections[2].Level)
+
+	data, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "test_broken: ticket service unavailable\n")
+	assert.Contains(t, string(data), `test_missing: unknown section "test_missing"`)
+}
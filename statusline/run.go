@@ -0,0 +1,115 @@
+package statusline
+
+import (
+	"cmp"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/bjulian5/claudestatusline/event"
+	"github.com/bjulian5/claudestatusline/render"
+)
+
+// Main runs the status line command: it reads the hook event from stdin and
+// prints the status line in the format chosen by flags and config. Programs
+// that register their own providers call it from their main function.
+func Main() {
+	format := flag.String("format", "", "output format: "+strings.Join(render.Formats(), ", ")+" or a template (default from config, else text)")
+	width := flag.Int("width", 0, "maximum width in columns (default $COLUMNS, unlimited if unset)")
+	noColor := flag.Bool("no-color", false, "disable colors")
+	flag.Parse()
+
+	if *format != "" && !IsTemplate(*format) {
+		if _, err := render.GetRenderer(*format); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+
+	log := NewDebugLog()
+	event, eventErr := decodeEvent(os.Stdin)
+	cfg, cfgErr := DefaultConfig(), error(nil)
+	if eventErr == nil {
+		cfg, cfgErr = LoadConfig(cmp.Or(event.Workspace.ProjectDir, event.Workspace.CurrentDir))
+	}
+	log.Log("event", eventErr)
+	log.Log("config", cfgErr)
+	outputFormat := cmp.Or(*format, cfg.Format, render.FormatText)
+	colorMode := render.DetectColorMode(*noColor, cfg.Color)
+	color.NoColor = colorMode == render.ColorNone
+
+	var rows []*render.StatusLine
+	switch {
+	case eventErr != nil:
+		rows = errorStatusLine("event", eventErr)
+	case IsTemplate(outputFormat):
+		text, err := ExecuteTemplate(outputFormat, NewTemplateData(event, cfg, colorMode))
+		if err == nil {
+			if cfgErr != nil {
+				text += render.DefaultSeparator + ConfigErrorSection(cfgErr).String()
+			}
+			fmt.Println(text)
+			return
+		}
+		log.Log("format", err)
+		rows = errorStatusLine("format", err)
+	default:
+		rows = buildStatusLines(event, cfg, cfgErr, log)
+	}
+
+	// Errors of a template format are marked in text.
+	if IsTemplate(outputFormat) {
+		outputFormat = render.FormatText
+	}
+	renderer, err := render.GetRenderer(outputFormat)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	for _, row := range rows {
+		row.ApplyColorMode(colorMode)
+		if outputFormat != render.FormatJSON {
+			row.Fit(render.TerminalWidth(*width), render.GetWidthFunc(outputFormat))
+		}
+		if err := renderer(os.Stdout, row); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+}
+
+func decodeEvent(r io.Reader) (*event.StatusHookEvent, error) {
+	var e event.StatusHookEvent
+	if err := json.NewDecoder(r).Decode(&e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// buildStatusLines builds each row of the status line. Failures are reported
+// as error markers so they reach the user in whichever format was requested,
+// with the details in log.
+func buildStatusLines(event *event.StatusHookEvent, cfg *Config, cfgErr error, log *DebugLog) []*render.StatusLine {
+	rows, err := NewStatusLinesFromEvent(event, cfg)
+	if err != nil {
+		log.Log("statusline", err)
+		return errorStatusLine("statusline", err)
+	}
+	if cfgErr != nil {
+		last := rows[len(rows)-1]
+		last.Sections = append(last.Sections, ConfigErrorSection(cfgErr))
+	}
+	return rows
+}
+
+// errorStatusLine is the output when nothing but the marker for the failed
+// step called name can be shown.
+func errorStatusLine(name string, err error) []*render.StatusLine {
+	return []*render.StatusLine{{Sections: []render.Section{render.ErrorSection(name, err)}}}
+}
@@ -0,0 +1,332 @@
+// Package statusline builds the Claude Code status line: it loads the
+// config, runs a SectionProvider for each section in the layout and renders
+// the result. Programs can add sections of their own with Register and then
+// call Main:
+//
+//	func main() {
+//		statusline.Register("ticket", statusline.SectionProviderFunc(ticketSection))
+//		statusline.Main()
+//	}
+package statusline
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/fatih/color"
+
+	"github.com/bjulian5/claudestatusline/event"
+	"github.com/bjulian5/claudestatusline/filecache"
+	"github.com/bjulian5/claudestatusline/git"
+	"github.com/bjulian5/claudestatusline/render"
+	"github.com/bjulian5/claudestatusline/transcript"
+)
+
+// sectionProviders are the built-in sections.
+var sectionProviders = map[string]SectionProvider{
+	SectionUser:      SectionProviderFunc(buildUserSection),
+	SectionDirectory: SectionProviderFunc(buildDirectorySection),
+	SectionGit:       SectionProviderFunc(buildGitSection),
+	SectionModel:     SectionProviderFunc(buildModelSection),
+	SectionCost:      SectionProviderFunc(buildCostSection),
+	SectionContext:   SectionProviderFunc(buildContextSection),
+	SectionBurnRate:  SectionProviderFunc(buildBurnRateSection),
+	SectionBudget:    SectionProviderFunc(buildBudgetSection),
+	SectionBlock:     SectionProviderFunc(buildBlockSection),
+	SectionBreakdown: SectionProviderFunc(buildBreakdownSection),
+}
+
+// NewStatusLineFromEvent builds the single row configured by cfg.Sections.
+func NewStatusLineFromEvent(event *event.StatusHookEvent, cfg *Config) (*render.StatusLine, error) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.deadline())
+	defer cancel()
+	groups, err := buildSections(ctx, event, cfg, cfg.Sections)
+	if err != nil {
+		return nil, err
+	}
+	return &render.StatusLine{
+		Separator: cmp.Or(cfg.Separator, render.DefaultSeparator),
+		Sections:  groups[0],
+	}, nil
+}
+
+// NewStatusLinesFromEvent builds every row of the configured layout.
+func NewStatusLinesFromEvent(event *event.StatusHookEvent, cfg *Config) ([]*render.StatusLine, error) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+
+	layout := cfg.Layout()
+	var configs [][]SectionConfig
+	for _, row := range layout {
+		configs = append(configs, row.Sections, row.Right)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.deadline())
+	defer cancel()
+	groups, err := buildSections(ctx, event, cfg, configs...)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []*render.StatusLine
+	for i, row := range layout {
+		lines = append(lines, &render.StatusLine{
+			Separator: cmp.Or(row.Separator, cfg.Separator, render.DefaultSeparator),
+			Sections:  groups[2*i],
+			Right:     groups[2*i+1],
+		})
+	}
+	return lines, nil
+}
+
+// buildSections builds each group of sections. All providers run at once and
+// the ones that miss ctx's deadline are shown with their last value, or a
+// placeholder, so that one slow section cannot hold up the status line. A
+// section that fails is shown as an error marker and its error goes to the
+// debug log.
+func buildSections(ctx context.Context, event *event.StatusHookEvent, cfg *Config, configs ...[]SectionConfig) ([][]render.Section, error) {
+	theme, err := cfg.ResolveTheme()
+	if err != nil {
+		return nil, err
+	}
+
+	var providers []SectionProvider
+	for _, group := range configs {
+		for _, sectionConfig := range group {
+			if !sectionConfig.IsEnabled() {
+				continue
+			}
+			provider, ok := cfg.sectionProvider(sectionConfig.Name)
+			if !ok {
+				provider = unknownSection(sectionConfig.Name)
+			}
+			providers = append(providers, provider)
+		}
+	}
+
+	results := runProviders(ctx, event, cfg, providers)
+
+	cache := filecache.New()
+	log := NewDebugLog()
+	groups := make([][]render.Section, len(configs))
+	next := 0
+	for i, group := range configs {
+		for _, sectionConfig := range group {
+			if !sectionConfig.IsEnabled() {
+				continue
+			}
+			result := results[next]
+			next++
+
+			key := sectionCacheKey(sectionConfig.Name, event)
+			var section render.Section
+			switch {
+			case !result.Done:
+				section = fallbackSection(cache, key)
+			case result.Err != nil:
+				// The marker keeps the section's priority but not its
+				// configured icon and colors, so it always reads as an error.
+				log.Log(sectionConfig.Name, result.Err)
+				section = render.ErrorSection(sectionConfig.Name, result.Err)
+				section.Priority = sectionConfig.priority()
+				theme.Apply(&section)
+				groups[i] = append(groups[i], section)
+				continue
+			case result.Section == nil:
+				continue
+			default:
+				section = *result.Section
+				storeSection(cache, key, section)
+			}
+
+			section.Name = sectionConfig.Name
+			section.Priority = sectionConfig.priority()
+			theme.Apply(&section)
+			groups[i] = append(groups[i], sectionConfig.Apply(section))
+		}
+	}
+	return groups, nil
+}
+
+// unknownSection fails with the reason a section name has no provider.
+func unknownSection(name string) SectionProvider {
+	return SectionProviderFunc(func(ctx context.Context, event *event.StatusHookEvent, cfg *Config) (*render.Section, error) {
+		return nil, fmt.Errorf("unknown section %q", name)
+	})
+}
+
+func buildUserSection(ctx context.Context, event *event.StatusHookEvent, cfg *Config) (*render.Section, error) {
+	user := cmp.Or(os.Getenv("USER"), "unknown")
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hostname: %w", err)
+	}
+
+	return &render.Section{
+		Icon:    "",
+		Content: fmt.Sprintf("%s@%s", user, hostname),
+		Short:   user,
+		Values:  map[string]any{"user": user, "hostname": hostname},
+	}, nil
+}
+
+func buildDirectorySection(ctx context.Context, event *event.StatusHookEvent, cfg *Config) (*render.Section, error) {
+	return &render.Section{
+		Icon:    "",
+		Content: path.Base(event.Workspace.CurrentDir),
+		Color:   color.New(color.FgCyan),
+		Values:  map[string]any{"path": event.Workspace.CurrentDir},
+	}, nil
+}
+
+func buildGitSection(ctx context.Context, event *event.StatusHookEvent, cfg *Config) (*render.Section, error) {
+	info, err := git.GetInfo(event.Workspace.CurrentDir, cfg.Git.Options())
+	if errors.Is(err, git.ErrNotRepository) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &render.Section{
+		Icon:    " ",
+		Content: info.String(),
+		Short:   info.Branch,
+		Color:   color.New(color.FgMagenta),
+		Values:  info.Values(),
+	}, nil
+}
+
+func buildModelSection(ctx context.Context, event *event.StatusHookEvent, cfg *Config) (*render.Section, error) {
+	return &render.Section{
+		Icon:    " ",
+		Content: event.Model.DisplayName,
+		Color:   color.New(color.FgGreen),
+		Values:  map[string]any{"id": event.Model.ID, "display_name": event.Model.DisplayName},
+	}, nil
+}
+
+func buildCostSection(ctx context.Context, event *event.StatusHookEvent, cfg *Config) (*render.Section, error) {
+	return &render.Section{
+		Icon:    "",
+		Content: fmt.Sprintf("%.4f", event.Cost.TotalCostUSD),
+		Short:   fmt.Sprintf("%.2f", event.Cost.TotalCostUSD),
+		Color:   color.New(color.FgYellow),
+		Values: map[string]any{
+			"total_cost_usd":    event.Cost.TotalCostUSD,
+			"total_duration_ms": event.Cost.TotalDurationMS,
+		},
+	}, nil
+}
+
+func buildContextSection(ctx context.Context, event *event.StatusHookEvent, cfg *Config) (*render.Section, error) {
+	context, err := loadContext(event, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	section := context.ToSection()
+	return &section, nil
+}
+
+// loadContext reads the context window usage of the session's transcript.
+func loadContext(event *event.StatusHookEvent, cfg *Config) (*ContextInfo, error) {
+	tp := transcript.NewParser()
+	tp.MaxTokenCount = cfg.ModelRegistry().MaxTokens(event.Model.ID)
+	tp.Cache = filecache.New()
+	tp.SessionID = event.SessionID
+	usage, err := tp.ParseContextFromTranscript(event.TranscriptPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse context from transcript: %w", err)
+	}
+	return &ContextInfo{ContextUsage: *usage, Bar: cfg.Context.ProgressBar()}, nil
+}
+
+func buildBurnRateSection(ctx context.Context, event *event.StatusHookEvent, cfg *Config) (*render.Section, error) {
+	burn := NewBurnRate(event.Cost, cfg.BurnRate)
+	if burn.IsWarmingUp() {
+		return nil, nil
+	}
+
+	section := burn.ToSection()
+	section.Icon = ""
+	return &section, nil
+}
+
+func buildBudgetSection(ctx context.Context, event *event.StatusHookEvent, cfg *Config) (*render.Section, error) {
+	ledger, err := NewLedger()
+	if err != nil {
+		return nil, err
+	}
+
+	spend, err := ledger.Record(event.SessionID, event.Cost.TotalCostUSD)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record session cost: %w", err)
+	}
+
+	budget := &BudgetInfo{
+		Spend:        *spend,
+		DailyBudget:  cfg.Budget.Daily,
+		WeeklyBudget: cfg.Budget.Weekly,
+	}
+	section := budget.ToSection()
+	return &section, nil
+}
+
+func buildBlockSection(ctx context.Context, event *event.StatusHookEvent, cfg *Config) (*render.Section, error) {
+	scanner, err := newBlockScanner(cfg.Blocks, cfg.ModelRegistry())
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := scanner.CurrentBlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find usage block: %w", err)
+	}
+	if block == nil {
+		return nil, nil
+	}
+
+	info := &BlockInfo{Block: block, Now: scanner.Now()}
+	section := info.ToSection()
+	section.Icon = ""
+	return &section, nil
+}
+
+func buildBreakdownSection(ctx context.Context, event *event.StatusHookEvent, cfg *Config) (*render.Section, error) {
+	tp := transcript.NewParser()
+	tp.Models = cfg.ModelRegistry()
+	tp.Cache = filecache.New()
+	tp.SessionID = event.SessionID
+	breakdown, err := tp.ParseCostFromTranscript(event.TranscriptPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute cost from transcript: %w", err)
+	}
+
+	info := &CostBreakdownInfo{
+		Breakdown:   *breakdown,
+		ReportedUSD: event.Cost.TotalCostUSD,
+		Tolerance:   cmp.Or(cfg.Breakdown.Tolerance, DefaultCostTolerance),
+	}
+	section := info.ToSection()
+	return &section, nil
+}
+
+// SectionConfigError names the section added when the config fails to load.
+const SectionConfigError = "config_error"
+
+// ConfigErrorSection marks a configuration problem in the status line
+// rather than silently ignoring it. The details are left to the debug log.
+func ConfigErrorSection(err error) render.Section {
+	section := render.ErrorSection(SectionConfigError, err)
+	section.Content = "config"
+	section.Priority = maxPriority
+	return section
+}
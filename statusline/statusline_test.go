@@ -0,0 +1,173 @@
+package statusline
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/claudestatusline/event"
+	"github.com/bjulian5/claudestatusline/render"
+)
+
+func TestNewStatusLineFromEvent(t *testing.T) {
+	useTempCache(t)
+
+	t.Run("successful creation", func(t *testing.T) {
+		event := &event.StatusHookEvent{
+			TranscriptPath: "/tmp/nonexistent.jsonl",
+			Model: event.Model{
+				DisplayName: "Claude 3",
+			},
+			Workspace: event.Workspace{
+				CurrentDir: "/home/user/project",
+			},
+			Cost: event.Cost{
+				TotalCostUSD: 0.0542,
+			},
+		}
+
+		statusLine, err := NewStatusLineFromEvent(event, nil)
+		require.NoError(t, err)
+		require.NotNil(t, statusLine)
+
+		assert.Equal(t, " | ", statusLine.Separator)
+		assert.Len(t, statusLine.Sections, 5, "Should have 5 sections: user, dir, model, cost, context")
+
+		assert.Contains(t, statusLine.Sections[0].Content, "@")
+		assert.Equal(t, "project", statusLine.Sections[1].Content)
+		assert.Equal(t, "Claude 3", statusLine.Sections[2].Content)
+		assert.Equal(t, "0.0542", statusLine.Sections[3].Content)
+	})
+}
+
+func TestNewStatusLineFromEventWithConfig(t *testing.T) {
+	useTempCache(t)
+
+	event := &event.StatusHookEvent{
+		TranscriptPath: "/tmp/nonexistent.jsonl",
+		Model:          event.Model{DisplayName: "Claude 3"},
+		Workspace:      event.Workspace{CurrentDir: "/home/user/project"},
+		Cost:           event.Cost{TotalCostUSD: 0.0542},
+	}
+
+	icon := "$"
+	disabled := false
+	cfg := &Config{
+		Separator: " :: ",
+		Sections: []SectionConfig{
+			{Name: SectionCost, Icon: &icon, Color: "red"},
+			{Name: SectionUser, Enabled: &disabled},
+			{Name: SectionModel},
+		},
+	}
+
+	statusLine, err := NewStatusLineFromEvent(event, cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, " :: ", statusLine.Separator)
+	require.Len(t, statusLine.Sections, 2)
+	assert.Equal(t, "$", statusLine.Sections[0].Icon)
+	assert.Equal(t, "0.0542", statusLine.Sections[0].Content)
+	assert.Equal(t, color.New(color.FgRed), statusLine.Sections[0].Color)
+	assert.Equal(t, SectionCost, statusLine.Sections[0].Name)
+	assert.Equal(t, 0.0542, statusLine.Sections[0].Values["total_cost_usd"])
+	assert.Equal(t, "Claude 3", statusLine.Sections[1].Content)
+	assert.Equal(t, SectionModel, statusLine.Sections[1].Name)
+}
+
+func TestNewStatusLineFromEventWithTheme(t *testing.T) {
+	useTempCache(t)
+
+	event := &event.StatusHookEvent{
+		Model:     event.Model{DisplayName: "Claude 3"},
+		Workspace: event.Workspace{CurrentDir: "/home/user/project"},
+	}
+
+	cfg := &Config{
+		Theme: "solarized",
+		Sections: []SectionConfig{
+			{Name: SectionDirectory},
+			{Name: SectionModel, Color: "red"},
+		},
+	}
+
+	statusLine, err := NewStatusLineFromEvent(event, cfg)
+	require.NoError(t, err)
+	require.Len(t, statusLine.Sections, 2)
+	assert.Equal(t, color.RGB(0x26, 0x8b, 0xd2), statusLine.Sections[0].Color)
+	assert.Equal(t, color.New(color.FgRed), statusLine.Sections[1].Color, "a section color overrides the theme")
+}
+
+func TestNewStatusLinesFromEvent(t *testing.T) {
+	useTempCache(t)
+
+	event := &event.StatusHookEvent{
+		TranscriptPath: "/tmp/nonexistent.jsonl",
+		Model:          event.Model{DisplayName: "Claude 3"},
+		Workspace:      event.Workspace{CurrentDir: "/home/user/project"},
+		Cost:           event.Cost{TotalCostUSD: 0.0542},
+	}
+
+	cfg := &Config{
+		Separator: " :: ",
+		Rows: []RowConfig{
+			{Sections: []SectionConfig{{Name: SectionDirectory}}, Right: []SectionConfig{{Name: SectionCost}}},
+			{Separator: " · ", Sections: []SectionConfig{{Name: SectionModel}, {Name: SectionCost}}},
+		},
+	}
+
+	rows, err := NewStatusLinesFromEvent(event, cfg)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+
+	assert.Equal(t, " :: ", rows[0].Separator)
+	require.Len(t, rows[0].Sections, 1)
+	assert.Equal(t, "project", rows[0].Sections[0].Content)
+	require.Len(t, rows[0].Right, 1)
+	assert.Equal(t, "0.0542", rows[0].Right[0].Content)
+
+	assert.Equal(t, " · ", rows[1].Separator)
+	assert.Len(t, rows[1].Sections, 2)
+	assert.Empty(t, rows[1].Right)
+
+	rows, err = NewStatusLinesFromEvent(event, nil)
+	require.NoError(t, err)
+	assert.Len(t, rows, 1, "the default layout is a single row")
+}
+
+func TestGitSection(t *testing.T) {
+	useTempCache(t)
+	logPath := filepath.Join(t.TempDir(), "debug.log")
+	t.Setenv(debugLogEnv, logPath)
+
+	cfg := DefaultConfig()
+	cfg.Sections = []SectionConfig{{Name: SectionModel}, {Name: SectionGit}}
+
+	t.Run("outside a repository", func(t *testing.T) {
+		event := &event.StatusHookEvent{Workspace: event.Workspace{CurrentDir: t.TempDir()}}
+		line, err := NewStatusLineFromEvent(event, cfg)
+		require.NoError(t, err)
+		require.Len(t, line.Sections, 1, "the git section is left out")
+		assert.NoFileExists(t, logPath)
+	})
+
+	t.Run("corrupt repository", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(dir, ".git"), 0755))
+
+		event := &event.StatusHookEvent{Workspace: event.Workspace{CurrentDir: dir}}
+		line, err := NewStatusLineFromEvent(event, cfg)
+		require.NoError(t, err)
+		require.Len(t, line.Sections, 2)
+		assert.Equal(t, "git", line.Sections[1].Content)
+		assert.Equal(t, render.LevelError, line.Sections[1].Level)
+
+		data, err := os.ReadFile(logPath)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "git: failed to read HEAD file")
+	})
+}
@@ -0,0 +1,358 @@
+package statusline
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/bjulian5/claudestatusline/event"
+	"github.com/bjulian5/claudestatusline/git"
+	"github.com/bjulian5/claudestatusline/render"
+)
+
+// IsTemplate reports whether a format is a text/template rather than the
+// name of an output format.
+func IsTemplate(format string) bool {
+	return strings.Contains(format, "{{")
+}
+
+// TemplateData is what a format template is executed against. The event's
+// fields are available directly, e.g. {{.Model.DisplayName}}. Context, Git
+// and Section are only computed when the template uses them, all at once and
+// within the configured deadline, like the sections of the status line.
+type TemplateData struct {
+	*event.StatusHookEvent
+
+	cfg      *Config
+	mode     render.ColorMode
+	ctx      context.Context
+	context  *pending[contextResult]
+	git      *pending[*git.Info]
+	sections map[string]*render.Section
+}
+
+type contextResult struct {
+	info *ContextInfo
+	err  error
+}
+
+func NewTemplateData(event *event.StatusHookEvent, cfg *Config, mode render.ColorMode) *TemplateData {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	return &TemplateData{
+		StatusHookEvent: event,
+		cfg:             cfg,
+		mode:            mode,
+		sections:        make(map[string]*render.Section),
+	}
+}
+
+// deadline bounds a read of the data. ExecuteTemplate shares one deadline
+// between every read of an execution; outside of it each read gets its own.
+func (d *TemplateData) deadline() (context.Context, context.CancelFunc) {
+	if d.ctx != nil {
+		return d.ctx, func() {}
+	}
+	return context.WithTimeout(context.Background(), d.cfg.deadline())
+}
+
+// Context returns the context window usage read from the transcript.
+func (d *TemplateData) Context() (*ContextInfo, error) {
+	ctx, cancel := d.deadline()
+	defer cancel()
+	result, ok := d.startContext().wait(ctx)
+	if !ok {
+		return nil, fmt.Errorf("reading the transcript: %w", ctx.Err())
+	}
+	return result.info, result.err
+}
+
+func (d *TemplateData) startContext() *pending[contextResult] {
+	if d.context == nil {
+		d.context = start(func() contextResult {
+			info, err := loadContext(d.StatusHookEvent, d.cfg)
+			return contextResult{info, err}
+		})
+	}
+	return d.context
+}
+
+// Git returns the repository state, or nil outside a git repository or when
+// it could not be read in time.
+func (d *TemplateData) Git() *git.Info {
+	ctx, cancel := d.deadline()
+	defer cancel()
+	info, _ := d.startGit().wait(ctx)
+	return info
+}
+
+func (d *TemplateData) startGit() *pending[*git.Info] {
+	if d.git == nil {
+		d.git = start(func() *git.Info {
+			info, _ := git.GetInfo(d.Workspace.CurrentDir, d.cfg.Git.Options())
+			return info
+		})
+	}
+	return d.git
+}
+
+// Section builds the named section as it would appear in the status line,
+// using its configured icon and colors if the layout includes it. It returns
+// nil when the section has nothing to show, and an error marker when it
+// fails.
+func (d *TemplateData) Section(name string) (*render.Section, error) {
+	if section, ok := d.sections[name]; ok {
+		return section, nil
+	}
+	if _, ok := d.cfg.sectionProvider(name); !ok {
+		return nil, fmt.Errorf("unknown section %q", name)
+	}
+
+	ctx, cancel := d.deadline()
+	defer cancel()
+	if err := d.buildSections(ctx, []string{name}); err != nil {
+		return nil, err
+	}
+	return d.sections[name], nil
+}
+
+// buildSections builds the named sections together and remembers them,
+// including the ones with nothing to show.
+func (d *TemplateData) buildSections(ctx context.Context, names []string) error {
+	var configs []SectionConfig
+	for _, name := range names {
+		configs = append(configs, d.sectionConfig(name))
+	}
+	groups, err := buildSections(ctx, d.StatusHookEvent, d.cfg, configs)
+	if err != nil {
+		return err
+	}
+
+	line := &render.StatusLine{Sections: groups[0]}
+	line.ApplyColorMode(d.mode)
+	for _, name := range names {
+		d.sections[name] = nil
+	}
+	for i := range line.Sections {
+		d.sections[line.Sections[i].Name] = &line.Sections[i]
+	}
+	return nil
+}
+
+// prefetch starts everything tmpl reads at once so that a template using
+// several sections waits for the slowest of them rather than their sum.
+// Failures are left for the template to run into when it reads the value.
+func (d *TemplateData) prefetch(ctx context.Context, tmpl *template.Template) {
+	uses := findTemplateUses(tmpl)
+	if uses.context {
+		d.startContext()
+	}
+	if uses.git {
+		d.startGit()
+	}
+
+	var names []string
+	for _, name := range uses.sections {
+		if _, ok := d.cfg.sectionProvider(name); ok && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) > 0 {
+		_ = d.buildSections(ctx, names)
+	}
+}
+
+// sectionConfig returns the first configuration of the named section in the
+// layout, enabled or not, since the template asks for it explicitly.
+func (d *TemplateData) sectionConfig(name string) SectionConfig {
+	for _, row := range d.cfg.Layout() {
+		for _, sectionConfig := range slices.Concat(row.Sections, row.Right) {
+			if sectionConfig.Name == name {
+				sectionConfig.Enabled = nil
+				return sectionConfig
+			}
+		}
+	}
+	return SectionConfig{Name: name}
+}
+
+// funcs are the helper functions available to templates.
+func (d *TemplateData) funcs() template.FuncMap {
+	return template.FuncMap{
+		"color":    d.colorize,
+		"tokens":   formatTokenCount,
+		"duration": func(ms int64) string { return formatDuration(time.Duration(ms) * time.Millisecond) },
+		"truncate": func(width int, s string) string { return render.TruncateWidth(s, width) },
+		"bar":      d.bar,
+	}
+}
+
+// colorize draws text in a color written like a section color, e.g.
+// "bold #ff8700".
+func (d *TemplateData) colorize(spec string, text any) (string, error) {
+	c, err := ParseColor(spec)
+	if err != nil {
+		return "", err
+	}
+	s := fmt.Sprint(text)
+	if c = render.DownsampleColor(c, d.mode); c == nil {
+		return s, nil
+	}
+	return c.Sprint(s), nil
+}
+
+// bar draws percentage with the configured context bar.
+func (d *TemplateData) bar(percentage float64) string {
+	section := render.Section{Spans: d.cfg.Context.ProgressBar().Spans(percentage)}
+	line := &render.StatusLine{Sections: []render.Section{section}}
+	line.ApplyColorMode(d.mode)
+	return line.Sections[0].String()
+}
+
+// ParseTemplate checks that text is a valid format template.
+func ParseTemplate(text string) (*template.Template, error) {
+	return template.New("format").
+		Option("missingkey=error").
+		Funcs(NewTemplateData(nil, nil, render.ColorNone).funcs()).
+		Parse(text)
+}
+
+// ExecuteTemplate renders text against data. The result has no trailing
+// newline; a template may produce several lines.
+func ExecuteTemplate(text string, data *TemplateData) (string, error) {
+	tmpl, err := ParseTemplate(text)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), data.cfg.deadline())
+	defer cancel()
+	data.ctx = ctx
+	defer func() { data.ctx = nil }()
+	data.prefetch(ctx, tmpl)
+
+	var b strings.Builder
+	if err := tmpl.Funcs(data.funcs()).Execute(&b, data); err != nil {
+		return "", err
+	}
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+// templateUses is what a template reads from its data.
+type templateUses struct {
+	context  bool
+	git      bool
+	sections []string
+}
+
+// findTemplateUses walks tmpl's parse tree for reads of Context and Git and
+// for calls of Section with a literal name. Reads it cannot see, such as a
+// section named by a variable, are still served when the template runs.
+func findTemplateUses(tmpl *template.Template) templateUses {
+	var uses templateUses
+	var walk func(node parse.Node)
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.IfNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.TemplateNode:
+			walk(n.Pipe)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				walk(cmd)
+			}
+		case *parse.CommandNode:
+			if len(n.Args) > 1 && slices.Equal(dataField(n.Args[0]), []string{"Section"}) {
+				if name, ok := n.Args[1].(*parse.StringNode); ok {
+					uses.sections = append(uses.sections, name.Text)
+				}
+			}
+			for _, arg := range n.Args {
+				walk(arg)
+			}
+		case *parse.ChainNode:
+			walk(n.Node)
+		case *parse.FieldNode, *parse.VariableNode:
+			if field := dataField(n); len(field) > 0 {
+				uses.context = uses.context || field[0] == "Context"
+				uses.git = uses.git || field[0] == "Git"
+			}
+		}
+	}
+
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			walk(t.Tree.Root)
+		}
+	}
+	return uses
+}
+
+// dataField returns the fields node reads from the data, as in .Git.Branch
+// or $.Git.Branch.
+func dataField(node parse.Node) []string {
+	switch n := node.(type) {
+	case *parse.FieldNode:
+		return n.Ident
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			return n.Ident[1:]
+		}
+	}
+	return nil
+}
+
+// pending is a value computed in the background.
+type pending[T any] struct {
+	done   chan T
+	value  T
+	ok     bool
+	waited bool
+}
+
+func start[T any](f func() T) *pending[T] {
+	p := &pending[T]{done: make(chan T, 1)}
+	go func() { p.done <- f() }()
+	return p
+}
+
+// wait returns the value, waiting for it no longer than ctx allows. ok is
+// false if it was not ready in time, and stays false.
+func (p *pending[T]) wait(ctx context.Context) (value T, ok bool) {
+	if !p.waited {
+		select {
+		case p.value = <-p.done:
+			p.ok = true
+		case <-ctx.Done():
+		}
+		p.waited = true
+	}
+	return p.value, p.ok
+}
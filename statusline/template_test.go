@@ -0,0 +1,141 @@
+package statusline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/claudestatusline/event"
+	"github.com/bjulian5/claudestatusline/render"
+)
+
+func TestIsTemplate(t *testing.T) {
+	assert.True(t, IsTemplate("{{.Model.DisplayName}}"))
+	assert.False(t, IsTemplate(render.FormatTmux))
+	assert.False(t, IsTemplate(""))
+}
+
+func TestExecuteTemplate(t *testing.T) {
+	useTempCache(t)
+
+	event := &event.StatusHookEvent{
+		TranscriptPath: "/tmp/nonexistent.jsonl",
+		Model:          event.Model{ID: "claude-sonnet-4-5", DisplayName: "Sonnet"},
+		Workspace:      event.Workspace{CurrentDir: t.TempDir()},
+		Cost:           event.Cost{TotalCostUSD: 1.25, TotalDurationMS: 5400000},
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{"event fields", `{{.Model.DisplayName}} ${{printf "%.2f" .Cost.TotalCostUSD}}`, "Sonnet $1.25"},
+		{"duration", `{{duration .Cost.TotalDurationMS}}`, "1h30m"},
+		{"tokens", `{{tokens 123456}}`, "123k"},
+		{"truncate", `{{truncate 4 "abcdefg"}}`, "abc…"},
+		{"bar", `{{bar 50}}`, "⛁⛁⛁⛁⛁⛶⛶⛶⛶⛶"},
+		{"color without color", `{{color "bold red" "hot"}}`, "hot"},
+		{"context", `{{with .Context}}{{tokens .Tokens}}/{{tokens .MaxTokenCount}}{{end}}`, "0/200k"},
+		{"git outside a repository", `{{with .Git}}{{.Branch}}{{else}}no repo{{end}}`, "no repo"},
+		{"section", `{{(.Section "cost").Content}} {{(.Section "cost").Level}}`, "1.2500 "},
+		{"several lines", "a\n{{.Model.DisplayName}}\n\n", "a\nSonnet"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, err := ExecuteTemplate(tt.template, NewTemplateData(event, nil, render.ColorNone))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, text)
+		})
+	}
+}
+
+func TestExecuteTemplateColor(t *testing.T) {
+	useTempCache(t)
+
+	color.NoColor = false
+	defer func() { color.NoColor = true }()
+	data := NewTemplateData(&event.StatusHookEvent{}, nil, render.Color256)
+
+	text, err := ExecuteTemplate(`{{color "#ff0000" "hot"}}`, data)
+	require.NoError(t, err)
+	assert.Contains(t, text, "\x1b[38;5;196mhot", "24-bit colors are downsampled")
+
+	_, err = ExecuteTemplate(`{{color "rainbow" "hot"}}`, data)
+	assert.ErrorContains(t, err, "rainbow")
+}
+
+func TestExecuteTemplateErrors(t *testing.T) {
+	useTempCache(t)
+
+	data := NewTemplateData(&event.StatusHookEvent{}, nil, render.ColorNone)
+
+	tests := []struct {
+		name     string
+		template string
+		err      string
+	}{
+		{"parse error", `{{.Model.DisplayName`, "unclosed action"},
+		{"unknown function", `{{humanize 1}}`, `function "humanize" not defined`},
+		{"unknown field", `{{.Nope}}`, "can't evaluate field Nope"},
+		{"unknown section", `{{.Section "weather"}}`, `unknown section "weather"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ExecuteTemplate(tt.template, data)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestFindTemplateUses(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		expected templateUses
+	}{
+		{"event only", `{{.Model.DisplayName}}`, templateUses{}},
+		{"context", `{{with .Context}}{{.Percentage}}{{end}}`, templateUses{context: true}},
+		{"git from root variable", `{{with .Model}}{{$.Git.Branch}}{{end}}`, templateUses{git: true}},
+		{
+			name:     "sections",
+			template: `{{(.Section "git").Content}}{{if true}}{{with .Section "model"}}{{.Content}}{{end}}{{end}}{{define "x"}}{{$.Section "cost"}}{{end}}`,
+			expected: templateUses{sections: []string{"git", "model", "cost"}},
+		},
+		{"section named by a variable", `{{$name := "git"}}{{.Section $name}}`, templateUses{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := ParseTemplate(tt.template)
+			require.NoError(t, err)
+			uses := findTemplateUses(tmpl)
+			assert.Equal(t, tt.expected.context, uses.context)
+			assert.Equal(t, tt.expected.git, uses.git)
+			assert.ElementsMatch(t, tt.expected.sections, uses.sections)
+		})
+	}
+}
+
+func TestExecuteTemplateDeadline(t *testing.T) {
+	useTempCache(t)
+
+	register(t, "test_a", staticProvider("a", 200*time.Millisecond))
+	register(t, "test_b", staticProvider("b", 200*time.Millisecond))
+	register(t, "test_slow", staticProvider("slow", time.Hour))
+
+	cfg := DefaultConfig()
+	cfg.Deadline = Duration(300 * time.Millisecond)
+	data := NewTemplateData(&event.StatusHookEvent{}, cfg, render.ColorNone)
+
+	start := time.Now()
+	text, err := ExecuteTemplate(`{{(.Section "test_a").Content}} {{(.Section "test_b").Content}} {{(.Section "test_slow").Content}}`, data)
+	require.NoError(t, err)
+	assert.Equal(t, "a b "+render.Ellipsis, text)
+	assert.Less(t, time.Since(start), 2*time.Duration(cfg.Deadline), "sections share one deadline")
+}
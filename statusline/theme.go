@@ -0,0 +1,109 @@
+package statusline
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/fatih/color"
+
+	"github.com/bjulian5/claudestatusline/render"
+)
+
+// Semantic roles a theme assigns colors to. Sections are colored by the role
+// of their section until their Level is warn or worse; sections without a
+// role of their own use the role of their level.
+const (
+	RoleUser   = "user"
+	RolePath   = "path"
+	RoleBranch = "branch"
+	RoleModel  = "model"
+	RoleCost   = "cost"
+	RoleUsage  = "usage"
+	RoleOK     = "ok"
+	RoleWarn   = "warn"
+	RoleCrit   = "crit"
+	RoleError  = "error"
+)
+
+const DefaultThemeName = "default"
+
+var themeRoles = []string{
+	RoleUser, RolePath, RoleBranch, RoleModel, RoleCost, RoleUsage,
+	RoleOK, RoleWarn, RoleCrit, RoleError,
+}
+
+var sectionRoles = map[string]string{
+	SectionUser:      RoleUser,
+	SectionDirectory: RolePath,
+	SectionGit:       RoleBranch,
+	SectionModel:     RoleModel,
+	SectionCost:      RoleCost,
+	SectionBurnRate:  RoleCost,
+	SectionBudget:    RoleCost,
+	SectionBreakdown: RoleCost,
+	SectionBlock:     RoleUsage,
+	SectionContext:   RoleUsage,
+}
+
+// ThemeConfig maps roles to colors, written like section colors, e.g.
+// "#268bd2" or "bold #dc322f". Roles a theme leaves out keep the section's
+// built-in color.
+type ThemeConfig map[string]string
+
+// builtinThemes are the named palettes. The default theme is empty so the
+// built-in basic colors are used.
+var builtinThemes = map[string]ThemeConfig{
+	DefaultThemeName: {},
+	"solarized": {
+		RoleUser: "#93a1a1", RolePath: "#268bd2", RoleBranch: "#6c71c4", RoleModel: "#859900",
+		RoleCost: "#b58900", RoleUsage: "#2aa198",
+		RoleOK: "#859900", RoleWarn: "#b58900", RoleCrit: "#dc322f", RoleError: "#dc322f",
+	},
+	"gruvbox": {
+		RoleUser: "#a89984", RolePath: "#83a598", RoleBranch: "#d3869b", RoleModel: "#b8bb26",
+		RoleCost: "#fabd2f", RoleUsage: "#8ec07c",
+		RoleOK: "#b8bb26", RoleWarn: "#fabd2f", RoleCrit: "#fb4934", RoleError: "#fb4934",
+	},
+	"catppuccin": {
+		RoleUser: "#bac2de", RolePath: "#89dceb", RoleBranch: "#cba6f7", RoleModel: "#a6e3a1",
+		RoleCost: "#f9e2af", RoleUsage: "#89b4fa",
+		RoleOK: "#a6e3a1", RoleWarn: "#f9e2af", RoleCrit: "#f38ba8", RoleError: "#f38ba8",
+	},
+	"high-contrast": {
+		RoleUser: "bold #ffffff", RolePath: "bold #00ffff", RoleBranch: "bold #ff00ff", RoleModel: "bold #00ff00",
+		RoleCost: "bold #ffff00", RoleUsage: "bold #5fafff",
+		RoleOK: "bold #00ff00", RoleWarn: "bold #ffff00", RoleCrit: "bold #ff0000", RoleError: "bold #ff0000",
+	},
+}
+
+// Theme is a resolved palette.
+type Theme map[string]*color.Color
+
+// NewTheme parses the colors of a theme config.
+func NewTheme(cfg ThemeConfig) (Theme, error) {
+	theme := make(Theme, len(cfg))
+	for _, role := range slices.Sorted(maps.Keys(cfg)) {
+		if !slices.Contains(themeRoles, role) {
+			return nil, fmt.Errorf("unknown role %q", role)
+		}
+		c, err := ParseColor(cfg[role])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", role, err)
+		}
+		theme[role] = c
+	}
+	return theme, nil
+}
+
+// Apply colors section according to its name or, when it is in trouble or
+// has no role of its own, its level.
+func (t Theme) Apply(section *render.Section) {
+	role, ok := sectionRoles[section.Name]
+	if section.Level != "" && (section.Level != render.LevelOK || !ok) {
+		role = string(section.Level)
+	}
+	if c, ok := t[role]; ok {
+		section.Color = c
+	}
+}
@@ -0,0 +1,64 @@
+package statusline
+
+import (
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/claudestatusline/render"
+)
+
+func TestBuiltinThemes(t *testing.T) {
+	for name, cfg := range builtinThemes {
+		t.Run(name, func(t *testing.T) {
+			theme, err := NewTheme(cfg)
+			require.NoError(t, err)
+			if name != DefaultThemeName {
+				for _, role := range themeRoles {
+					assert.Contains(t, theme, role, "built-in themes should define every role")
+				}
+			}
+		})
+	}
+}
+
+func TestNewTheme(t *testing.T) {
+	theme, err := NewTheme(ThemeConfig{RolePath: "#268bd2", RoleCrit: "bold red"})
+	require.NoError(t, err)
+	assert.Equal(t, color.RGB(0x26, 0x8b, 0xd2), theme[RolePath])
+	assert.Equal(t, color.New(color.Bold, color.FgRed), theme[RoleCrit])
+
+	_, err = NewTheme(ThemeConfig{"background": "#000000"})
+	assert.ErrorContains(t, err, `unknown role "background"`)
+
+	_, err = NewTheme(ThemeConfig{RolePath: "#12345"})
+	assert.ErrorContains(t, err, "path")
+}
+
+func TestThemeApply(t *testing.T) {
+	theme, err := NewTheme(builtinThemes["solarized"])
+	require.NoError(t, err)
+
+	dir := render.Section{Name: SectionDirectory, Color: color.New(color.FgCyan)}
+	theme.Apply(&dir)
+	assert.Equal(t, color.RGB(0x26, 0x8b, 0xd2), dir.Color)
+
+	context := render.Section{Name: SectionContext, Level: render.LevelCrit, Color: color.New(color.FgRed)}
+	theme.Apply(&context)
+	assert.Equal(t, color.RGB(0xdc, 0x32, 0x2f), context.Color, "the level's role takes precedence")
+
+	breakdown := render.Section{Name: SectionBreakdown, Level: render.LevelOK, Color: color.New(color.FgGreen)}
+	theme.Apply(&breakdown)
+	assert.Equal(t, color.RGB(0xb5, 0x89, 0x00), breakdown.Color, "a healthy section keeps its own role")
+
+	ticket := render.Section{Name: "ticket", Level: render.LevelOK}
+	theme.Apply(&ticket)
+	assert.Equal(t, color.RGB(0x85, 0x99, 0x00), ticket.Color, "a section without a role uses its level's")
+
+	partial := Theme{RoleModel: color.New(color.FgBlue)}
+	cost := render.Section{Name: SectionCost, Color: color.New(color.FgYellow)}
+	partial.Apply(&cost)
+	assert.Equal(t, color.New(color.FgYellow), cost.Color, "roles a theme leaves out keep the built-in color")
+}
@@ -0,0 +1,164 @@
+package transcript
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const DefaultMaxTokens = 200000
+
+// ModelSpec describes a model family. Prefix is matched against the
+// normalized model ID, so "claude-sonnet-4" covers every dated release.
+// A zero ContextWindow or nil Pricing means the spec has no opinion and a
+// less specific match is used instead.
+type ModelSpec struct {
+	Prefix        string
+	ContextWindow int
+	Pricing       *ModelPricing
+}
+
+// ModelConfig overrides or extends the built-in model table from config.
+type ModelConfig struct {
+	ContextWindow int           `json:"context_window,omitempty"`
+	Pricing       *ModelPricing `json:"pricing,omitempty"`
+}
+
+var (
+	opus45Pricing  = &ModelPricing{Input: 5, Output: 25, CacheWrite: 6.25, CacheRead: 0.5}
+	opusPricing    = &ModelPricing{Input: 15, Output: 75, CacheWrite: 18.75, CacheRead: 1.5}
+	sonnetPricing  = &ModelPricing{Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.3}
+	haiku45Pricing = &ModelPricing{Input: 1, Output: 5, CacheWrite: 1.25, CacheRead: 0.1}
+	haiku35Pricing = &ModelPricing{Input: 0.8, Output: 4, CacheWrite: 1, CacheRead: 0.08}
+	haiku3Pricing  = &ModelPricing{Input: 0.25, Output: 1.25, CacheWrite: 0.3, CacheRead: 0.03}
+	claude2Pricing = &ModelPricing{Input: 8, Output: 24}
+	instantPricing = &ModelPricing{Input: 0.8, Output: 2.4}
+)
+
+var knownModels = []ModelSpec{
+	{Prefix: "claude-opus-4-5", ContextWindow: 200000, Pricing: opus45Pricing},
+	{Prefix: "claude-opus-4", ContextWindow: 200000, Pricing: opusPricing},
+	{Prefix: "claude-sonnet-4", ContextWindow: 200000, Pricing: sonnetPricing},
+	{Prefix: "claude-haiku-4", ContextWindow: 200000, Pricing: haiku45Pricing},
+	{Prefix: "claude-3-7-sonnet", ContextWindow: 200000, Pricing: sonnetPricing},
+	{Prefix: "claude-3-5-sonnet", ContextWindow: 200000, Pricing: sonnetPricing},
+	{Prefix: "claude-3-5-haiku", ContextWindow: 200000, Pricing: haiku35Pricing},
+	{Prefix: "claude-3-opus", ContextWindow: 200000, Pricing: opusPricing},
+	{Prefix: "claude-3-sonnet", ContextWindow: 200000, Pricing: sonnetPricing},
+	{Prefix: "claude-3-haiku", ContextWindow: 200000, Pricing: haiku3Pricing},
+	{Prefix: "claude-2.1", ContextWindow: 200000, Pricing: claude2Pricing},
+	{Prefix: "claude-2", ContextWindow: 100000, Pricing: claude2Pricing},
+	{Prefix: "claude-instant", ContextWindow: 100000, Pricing: instantPricing},
+}
+
+// contextSuffix matches the "[1m]" style suffix Claude Code appends to model
+// IDs that run with an extended context window.
+var contextSuffix = regexp.MustCompile(`\[(\d+)([km])\]$`)
+
+type ModelRegistry struct {
+	models []ModelSpec
+}
+
+var defaultModelRegistry = NewModelRegistry(nil)
+
+// NewModelRegistry builds a registry from the built-in table plus overrides
+// keyed by model ID or ID prefix. Overrides win over built-in entries.
+func NewModelRegistry(overrides map[string]ModelConfig) *ModelRegistry {
+	models := make([]ModelSpec, 0, len(overrides)+len(knownModels))
+	for prefix, override := range overrides {
+		models = append(models, ModelSpec{
+			Prefix:        normalizeModelID(prefix),
+			ContextWindow: override.ContextWindow,
+			Pricing:       override.Pricing,
+		})
+	}
+	models = append(models, knownModels...)
+	return &ModelRegistry{models: models}
+}
+
+// Lookup returns the most specific model spec for modelID.
+func (r *ModelRegistry) Lookup(modelID string) (ModelSpec, bool) {
+	return r.match(modelID, func(ModelSpec) bool { return true })
+}
+
+// match returns the most specific spec for modelID that satisfies has.
+func (r *ModelRegistry) match(modelID string, has func(ModelSpec) bool) (ModelSpec, bool) {
+	id := normalizeModelID(modelID)
+
+	var best ModelSpec
+	found := false
+	for _, spec := range r.models {
+		if !strings.HasPrefix(id, spec.Prefix) || !has(spec) {
+			continue
+		}
+		if !found || len(spec.Prefix) > len(best.Prefix) {
+			best = spec
+			found = true
+		}
+	}
+	return best, found
+}
+
+// MaxTokens returns the context window for modelID, preferring configured
+// overrides, then an explicit size suffix, then the built-in table.
+func (r *ModelRegistry) MaxTokens(modelID string) int {
+	spec, found := r.match(modelID, func(spec ModelSpec) bool { return spec.ContextWindow > 0 })
+	if found && spec.Prefix == normalizeModelID(modelID) {
+		return spec.ContextWindow
+	}
+
+	if size, ok := parseContextSuffix(modelID); ok {
+		return size
+	}
+
+	if found {
+		return spec.ContextWindow
+	}
+	return DefaultMaxTokens
+}
+
+// Pricing returns the per-token prices for modelID, if known.
+func (r *ModelRegistry) Pricing(modelID string) (*ModelPricing, bool) {
+	spec, found := r.match(modelID, func(spec ModelSpec) bool { return spec.Pricing != nil })
+	return spec.Pricing, found
+}
+
+// Cost prices a single usage block. Usage for a model without known pricing
+// is counted as unpriced rather than free.
+func (r *ModelRegistry) Cost(modelID string, usage Usage) CostBreakdown {
+	pricing, found := r.Pricing(modelID)
+	if !found {
+		return CostBreakdown{UnpricedTokens: usage.Total()}
+	}
+	return pricing.Cost(usage)
+}
+
+func GetModelMaxTokens(modelID string) int {
+	return defaultModelRegistry.MaxTokens(modelID)
+}
+
+// normalizeModelID lowercases the ID and strips provider prefixes such as
+// Bedrock's "us.anthropic." and Vertex's "@date" version separator.
+func normalizeModelID(modelID string) string {
+	id := strings.ToLower(strings.TrimSpace(modelID))
+	if i := strings.Index(id, "claude-"); i > 0 {
+		id = id[i:]
+	}
+	return strings.Replace(id, "@", "-", 1)
+}
+
+func parseContextSuffix(modelID string) (int, bool) {
+	match := contextSuffix.FindStringSubmatch(strings.ToLower(modelID))
+	if match == nil {
+		return 0, false
+	}
+
+	size, err := strconv.Atoi(match[1])
+	if err != nil || size <= 0 {
+		return 0, false
+	}
+	if match[2] == "m" {
+		return size * 1000000, true
+	}
+	return size * 1000, true
+}
@@ -0,0 +1,102 @@
+package transcript
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetModelMaxTokens(t *testing.T) {
+	tests := []struct {
+		modelID  string
+		expected int
+	}{
+		{"claude-sonnet-4-5-20250929", 200000},
+		{"claude-sonnet-4-5-20250929[1m]", 1000000},
+		{"claude-sonnet-4[1M]", 1000000},
+		{"claude-opus-4-1-20250805", 200000},
+		{"claude-2.0", 100000},
+		{"claude-2.1", 200000},
+		{"us.anthropic.claude-3-5-haiku-20241022-v1:0", 200000},
+		{"claude-instant-1.2", 100000},
+		{"custom-model[500k]", 500000},
+		{"unknown-model", DefaultMaxTokens},
+		{"", DefaultMaxTokens},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.modelID, func(t *testing.T) {
+			assert.Equal(t, tt.expected, GetModelMaxTokens(tt.modelID))
+		})
+	}
+}
+
+func TestModelRegistryOverrides(t *testing.T) {
+	registry := NewModelRegistry(map[string]ModelConfig{
+		"claude-sonnet-4-5":     {ContextWindow: 400000},
+		"claude-sonnet-4-5[1m]": {ContextWindow: 750000},
+		"Internal-Model":        {ContextWindow: 32000},
+		"claude-opus-4-ignored": {},
+	})
+
+	assert.Equal(t, 400000, registry.MaxTokens("claude-sonnet-4-5-20250929"))
+	assert.Equal(t, 1000000, registry.MaxTokens("claude-sonnet-4-5-20250929[1m]"), "size suffix beats a family override")
+	assert.Equal(t, 750000, registry.MaxTokens("claude-sonnet-4-5[1m]"), "exact override beats the size suffix")
+	assert.Equal(t, 32000, registry.MaxTokens("internal-model-v2"))
+	assert.Equal(t, 200000, registry.MaxTokens("claude-opus-4-ignored"), "zero override falls back to the table")
+	assert.Equal(t, 200000, registry.MaxTokens("claude-sonnet-4-20250514"))
+}
+
+func TestModelRegistryLookup(t *testing.T) {
+	spec, found := defaultModelRegistry.Lookup("claude-3-5-sonnet-20241022")
+	assert.True(t, found)
+	assert.Equal(t, "claude-3-5-sonnet", spec.Prefix)
+
+	_, found = defaultModelRegistry.Lookup("gpt-4")
+	assert.False(t, found)
+}
+
+func TestModelRegistryPricing(t *testing.T) {
+	tests := []struct {
+		modelID  string
+		expected *ModelPricing
+	}{
+		{"claude-sonnet-4-5-20250929", sonnetPricing},
+		{"claude-opus-4-5-20251101", opus45Pricing},
+		{"claude-opus-4-1-20250805", opusPricing},
+		{"claude-3-5-haiku-20241022", haiku35Pricing},
+		{"claude-haiku-4-5", haiku45Pricing},
+		{"unknown-model", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.modelID, func(t *testing.T) {
+			pricing, found := defaultModelRegistry.Pricing(tt.modelID)
+			assert.Equal(t, tt.expected != nil, found)
+			assert.Equal(t, tt.expected, pricing)
+		})
+	}
+
+	custom := &ModelPricing{Input: 1, Output: 2}
+	registry := NewModelRegistry(map[string]ModelConfig{
+		"claude-sonnet-4-5": {ContextWindow: 300000},
+		"internal-model":    {Pricing: custom},
+	})
+	pricing, _ := registry.Pricing("claude-sonnet-4-5-20250929")
+	assert.Equal(t, sonnetPricing, pricing, "context-only override keeps built-in pricing")
+	pricing, _ = registry.Pricing("internal-model-v2")
+	assert.Equal(t, custom, pricing)
+	assert.Equal(t, DefaultMaxTokens, registry.MaxTokens("internal-model-v2"), "pricing-only override keeps the default window")
+}
+
+func TestModelRegistryCost(t *testing.T) {
+	usage := Usage{InputTokens: 1000000, OutputTokens: 1000000}
+
+	cost := defaultModelRegistry.Cost("claude-sonnet-4-20250514", usage)
+	assert.InDelta(t, 18.0, cost.Total(), 1e-9)
+	assert.Zero(t, cost.UnpricedTokens)
+
+	cost = defaultModelRegistry.Cost("mystery", usage)
+	assert.Zero(t, cost.Total())
+	assert.Equal(t, 2000000, cost.UnpricedTokens)
+}
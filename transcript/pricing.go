@@ -0,0 +1,40 @@
+package transcript
+
+// ModelPricing is the USD price per million tokens of each kind.
+type ModelPricing struct {
+	Input      float64 `json:"input"`
+	Output     float64 `json:"output"`
+	CacheWrite float64 `json:"cache_write"`
+	CacheRead  float64 `json:"cache_read"`
+}
+
+func (p *ModelPricing) Cost(usage Usage) CostBreakdown {
+	return CostBreakdown{
+		Input:      float64(usage.InputTokens) * p.Input / 1e6,
+		Output:     float64(usage.OutputTokens) * p.Output / 1e6,
+		CacheWrite: float64(usage.CacheCreationInputTokens) * p.CacheWrite / 1e6,
+		CacheRead:  float64(usage.CacheReadInputTokens) * p.CacheRead / 1e6,
+	}
+}
+
+// CostBreakdown splits a cost in USD by token kind. UnpricedTokens counts
+// usage from models with no known pricing.
+type CostBreakdown struct {
+	Input          float64 `json:"input"`
+	Output         float64 `json:"output"`
+	CacheWrite     float64 `json:"cache_write"`
+	CacheRead      float64 `json:"cache_read"`
+	UnpricedTokens int     `json:"unpriced_tokens,omitempty"`
+}
+
+func (c *CostBreakdown) Add(other CostBreakdown) {
+	c.Input += other.Input
+	c.Output += other.Output
+	c.CacheWrite += other.CacheWrite
+	c.CacheRead += other.CacheRead
+	c.UnpricedTokens += other.UnpricedTokens
+}
+
+func (c *CostBreakdown) Total() float64 {
+	return c.Input + c.Output + c.CacheWrite + c.CacheRead
+}
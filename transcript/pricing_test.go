@@ -0,0 +1,31 @@
+package transcript
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModelPricingCost(t *testing.T) {
+	pricing := &ModelPricing{Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.3}
+	cost := pricing.Cost(Usage{
+		InputTokens:              1000000,
+		OutputTokens:             100000,
+		CacheCreationInputTokens: 200000,
+		CacheReadInputTokens:     2000000,
+	})
+
+	assert.InDelta(t, 3.0, cost.Input, 1e-9)
+	assert.InDelta(t, 1.5, cost.Output, 1e-9)
+	assert.InDelta(t, 0.75, cost.CacheWrite, 1e-9)
+	assert.InDelta(t, 0.6, cost.CacheRead, 1e-9)
+	assert.InDelta(t, 5.85, cost.Total(), 1e-9)
+}
+
+func TestCostBreakdownAdd(t *testing.T) {
+	total := CostBreakdown{Input: 1, Output: 2}
+	total.Add(CostBreakdown{Input: 0.5, CacheWrite: 1, CacheRead: 0.25, UnpricedTokens: 10})
+
+	assert.Equal(t, CostBreakdown{Input: 1.5, Output: 2, CacheWrite: 1, CacheRead: 0.25, UnpricedTokens: 10}, total)
+	assert.InDelta(t, 4.75, total.Total(), 1e-9)
+}
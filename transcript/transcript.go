@@ -0,0 +1,395 @@
+// Package transcript reads token usage and cost from Claude Code session
+// transcripts, and knows the context window and pricing of each model.
+package transcript
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/bjulian5/claudestatusline/filecache"
+)
+
+// transcriptIdentityBytes is how much of the start of a transcript is hashed
+// to recognise when the file at a path has been replaced.
+const transcriptIdentityBytes = 1024
+
+const (
+	reverseChunkSize      = 64 * 1024
+	maxTranscriptLineSize = 10 * 1024 * 1024
+)
+
+type Parser struct {
+	GetTranscriptFile func(path string) (*os.File, error)
+	MaxTokenCount     int
+
+	// Cache, when set, remembers how far each transcript has been parsed so
+	// later calls only read lines appended since. SessionID is part of the
+	// cache key.
+	Cache     *filecache.Cache
+	SessionID string
+
+	// Models prices usage; the built-in table is used when nil.
+	Models *ModelRegistry
+}
+
+func NewParser() *Parser {
+	return &Parser{
+		GetTranscriptFile: os.Open,
+		MaxTokenCount:     DefaultMaxTokens,
+		Models:            defaultModelRegistry,
+	}
+}
+
+// Cursor is the parse progress persisted between calls. Offset is always at
+// the start of a line; a trailing line without a newline may still be in the
+// middle of being written, so it is not skipped next time.
+type Cursor struct {
+	Offset   int64  `json:"offset"`
+	Identity string `json:"identity"`
+}
+
+type transcriptState struct {
+	Cursor
+	Latest *Usage `json:"latest,omitempty"`
+}
+
+// costState accumulates the cost of every assistant response seen so far.
+// LastKey skips the repeated usage Claude Code logs for each content block of
+// a single response.
+type costState struct {
+	Cursor
+	Breakdown CostBreakdown `json:"breakdown"`
+	LastKey   string        `json:"last_key,omitempty"`
+}
+
+// ContextUsage is how much of the model's context window a session uses.
+type ContextUsage struct {
+	InputTokenCount  int
+	OutputTokenCount int
+	MaxTokenCount    int
+	Notes            string
+}
+
+// Tokens returns the number of tokens in the context window.
+func (c *ContextUsage) Tokens() int {
+	return c.InputTokenCount + c.OutputTokenCount
+}
+
+// Percentage returns how full the context window is, from 0 to 100.
+func (c *ContextUsage) Percentage() float64 {
+	if c.MaxTokenCount == 0 {
+		return 0
+	}
+	return float64(c.Tokens()) / float64(c.MaxTokenCount) * 100
+}
+
+func (t *Parser) ParseContextFromTranscript(transcriptPath string) (*ContextUsage, error) {
+	context := &ContextUsage{
+		MaxTokenCount: cmp.Or(t.MaxTokenCount, DefaultMaxTokens),
+	}
+	transcriptFile, err := t.GetTranscriptFile(transcriptPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return context, nil
+		}
+		return context, fmt.Errorf("failed to open transcript file: %w", err)
+	}
+
+	defer transcriptFile.Close()
+	cacheKey := "transcript:" + t.SessionID + ":" + transcriptPath
+	var state transcriptState
+	if _, found := t.Cache.Load(cacheKey, &state); !found || !state.matches(transcriptFile) {
+		state = transcriptState{}
+	}
+
+	info, err := transcriptFile.Stat()
+	if err != nil {
+		return context, fmt.Errorf("failed to stat transcript file: %w", err)
+	}
+
+	// Only the most recent assistant entry matters, so read backwards from
+	// the end and stop at the first one. Anything before the cached offset
+	// has already been seen.
+	reader := newReverseLineReader(transcriptFile, state.Offset, info.Size())
+	offset := info.Size()
+	for first := true; ; first = false {
+		line, complete, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			context.Notes = fmt.Sprintf("Error reading transcript: %v", err)
+			return context, fmt.Errorf("error reading transcript file: %w", err)
+		}
+		if first && !complete {
+			offset -= int64(len(line))
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			continue
+		}
+
+		if entry.Type == "assistant" && entry.Message.Role == "assistant" {
+			usage := entry.Message.Usage
+			state.Latest = &usage
+			break
+		}
+	}
+	state.Offset = offset
+
+	if t.Cache != nil {
+		state.Identity = transcriptIdentity(transcriptFile, state.Offset)
+		_ = t.Cache.Store(cacheKey, state)
+	}
+
+	if usage := state.Latest; usage != nil {
+		context.InputTokenCount = usage.InputTokens +
+			usage.CacheCreationInputTokens +
+			usage.CacheReadInputTokens
+		context.OutputTokenCount = usage.OutputTokens
+	}
+
+	return context, nil
+}
+
+// ParseCostFromTranscript prices every assistant response in the transcript
+// using the model registry. Only lines appended since the previous call are
+// read when a cache is set.
+func (t *Parser) ParseCostFromTranscript(transcriptPath string) (*CostBreakdown, error) {
+	transcriptFile, err := t.GetTranscriptFile(transcriptPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &CostBreakdown{}, nil
+		}
+		return nil, fmt.Errorf("failed to open transcript file: %w", err)
+	}
+	defer transcriptFile.Close()
+
+	cacheKey := "transcript-cost:" + t.SessionID + ":" + transcriptPath
+	var state costState
+	if _, found := t.Cache.Load(cacheKey, &state); !found || !state.matches(transcriptFile) {
+		state = costState{}
+	}
+	if _, err := transcriptFile.Seek(state.Offset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek transcript file: %w", err)
+	}
+
+	models := cmp.Or(t.Models, defaultModelRegistry)
+	state.Offset, err = scanLines(transcriptFile, state.Offset, func(line []byte) {
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return
+		}
+		if entry.Type != "assistant" || entry.Message.Role != "assistant" {
+			return
+		}
+
+		if key := entry.DedupKey(); key != "" {
+			if key == state.LastKey {
+				return
+			}
+			state.LastKey = key
+		}
+		state.Breakdown.Add(models.Cost(entry.Message.Model, entry.Message.Usage))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Cache != nil {
+		state.Identity = transcriptIdentity(transcriptFile, state.Offset)
+		_ = t.Cache.Store(cacheKey, state)
+	}
+	return &state.Breakdown, nil
+}
+
+// matches reports whether the cursor still describes file. A file that was
+// truncated or replaced needs a full rescan.
+func (c Cursor) matches(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil || info.Size() < c.Offset {
+		return false
+	}
+	return c.Identity == transcriptIdentity(file, c.Offset)
+}
+
+// transcriptIdentity hashes the start of the file, up to offset, so that a
+// new transcript written to the same path is not mistaken for a continuation.
+func transcriptIdentity(file *os.File, offset int64) string {
+	buf := make([]byte, min(offset, transcriptIdentityBytes))
+	n, _ := file.ReadAt(buf, 0)
+	sum := sha256.Sum256(buf[:n])
+	return hex.EncodeToString(sum[:])
+}
+
+// reverseLineReader returns the lines of file[start:end) from last to first,
+// reading in chunks so the cost depends on how far back it has to look rather
+// than on the size of the file.
+type reverseLineReader struct {
+	file  *os.File
+	start int64
+	pos   int64
+	buf   []byte
+}
+
+func newReverseLineReader(file *os.File, start, end int64) *reverseLineReader {
+	return &reverseLineReader{file: file, start: start, pos: end}
+}
+
+// Next returns the previous line without its newline and whether it was
+// newline terminated. Only the last line in the range can be incomplete.
+// It returns io.EOF once start is reached.
+func (r *reverseLineReader) Next() ([]byte, bool, error) {
+	for {
+		content := r.buf
+		complete := len(content) > 0 && content[len(content)-1] == '\n'
+		if complete {
+			content = content[:len(content)-1]
+		}
+
+		if i := bytes.LastIndexByte(content, '\n'); i >= 0 {
+			r.buf = r.buf[:i+1]
+			return content[i+1:], complete, nil
+		}
+
+		if r.pos == r.start {
+			if len(r.buf) == 0 {
+				return nil, false, io.EOF
+			}
+			r.buf = nil
+			return content, complete, nil
+		}
+
+		if len(r.buf) > maxTranscriptLineSize {
+			return nil, false, bufio.ErrTooLong
+		}
+		if err := r.readChunk(); err != nil {
+			return nil, false, err
+		}
+	}
+}
+
+// readChunk prepends the preceding block of the file to buf. The block grows
+// with buf so very long lines are read in a logarithmic number of steps.
+func (r *reverseLineReader) readChunk() error {
+	size := min(max(int64(reverseChunkSize), int64(len(r.buf))), r.pos-r.start)
+	chunk := make([]byte, size, size+int64(len(r.buf)))
+	if _, err := r.file.ReadAt(chunk, r.pos-size); err != nil {
+		return err
+	}
+	r.pos -= size
+	r.buf = append(chunk, r.buf...)
+	return nil
+}
+
+// ReadAssistantEntriesFrom returns the assistant entries appended to the
+// transcript since cursor, in file order, and advances cursor past them. A
+// cursor that no longer matches the file, because it was truncated or
+// replaced, is reset and the whole file is read; reset reports this so that
+// entries collected before can be discarded.
+func (t *Parser) ReadAssistantEntriesFrom(transcriptPath string, cursor *Cursor) (entries []Entry, reset bool, err error) {
+	transcriptFile, err := t.GetTranscriptFile(transcriptPath)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to open transcript file: %w", err)
+	}
+	defer transcriptFile.Close()
+
+	if *cursor != (Cursor{}) && !cursor.matches(transcriptFile) {
+		*cursor = Cursor{}
+		reset = true
+	}
+	if _, err := transcriptFile.Seek(cursor.Offset, io.SeekStart); err != nil {
+		return nil, reset, fmt.Errorf("failed to seek transcript file: %w", err)
+	}
+
+	offset, err := scanLines(transcriptFile, cursor.Offset, func(line []byte) {
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return
+		}
+		if entry.Type == "assistant" && entry.Message.Role == "assistant" {
+			entries = append(entries, entry)
+		}
+	})
+	if err != nil {
+		return nil, reset, err
+	}
+	cursor.Offset = offset
+	cursor.Identity = transcriptIdentity(transcriptFile, offset)
+	return entries, reset, nil
+}
+
+// scanLines calls fn with each complete line of file from its current
+// position, which is offset, and returns the offset after the last one. A
+// trailing line without a newline may still be being written and is left for
+// the next call.
+func scanLines(file *os.File, offset int64, fn func(line []byte)) (int64, error) {
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxTranscriptLineSize)
+	var lineLength int
+	var lineComplete bool
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		if token != nil {
+			lineLength = advance
+			lineComplete = data[advance-1] == '\n'
+		}
+		return advance, token, err
+	})
+
+	for scanner.Scan() {
+		if !lineComplete {
+			break
+		}
+		offset += int64(lineLength)
+		fn(scanner.Bytes())
+	}
+
+	if err := scanner.Err(); err != nil {
+		return offset, fmt.Errorf("error reading transcript file: %w", err)
+	}
+	return offset, nil
+}
+
+type Entry struct {
+	ParentUUID string  `json:"parentUuid"`
+	UUID       string  `json:"uuid"`
+	Type       string  `json:"type"`
+	Timestamp  string  `json:"timestamp"`
+	RequestID  string  `json:"requestId"`
+	CostUSD    float64 `json:"costUSD"`
+	Message    struct {
+		ID    string `json:"id"`
+		Model string `json:"model"`
+		Role  string `json:"role"`
+		Usage Usage  `json:"usage"`
+	} `json:"message"`
+}
+
+// DedupKey identifies the API response an entry belongs to, or returns an
+// empty string if the entry carries no IDs.
+func (e *Entry) DedupKey() string {
+	if e.Message.ID == "" && e.RequestID == "" {
+		return ""
+	}
+	return e.Message.ID + ":" + e.RequestID
+}
+
+type Usage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+func (u Usage) Total() int {
+	return u.InputTokens + u.OutputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+}
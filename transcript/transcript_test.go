@@ -0,0 +1,541 @@
+package transcript
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/claudestatusline/filecache"
+)
+
+func TestParserParseContextFromTranscript(t *testing.T) {
+	tests := []struct {
+		name              string
+		transcriptContent string
+		fileError         error
+		expectedInput     int
+		expectedOutput    int
+		expectedNotes     string
+		expectError       bool
+	}{
+		{
+			name: "successful parse with assistant message",
+			transcriptContent: `{"type":"user","message":{"role":"user"}}
+{"type":"assistant","message":{"role":"assistant","usage":{"input_tokens":5000,"output_tokens":2000,"cache_creation_input_tokens":1000,"cache_read_input_tokens":500}}}`,
+			expectedInput:  6500,
+			expectedOutput: 2000,
+		},
+		{
+			name: "multiple assistant messages uses most recent",
+			transcriptContent: `{"type":"assistant","message":{"role":"assistant","usage":{"input_tokens":1000,"output_tokens":500}}}
+{"type":"assistant","message":{"role":"assistant","usage":{"input_tokens":5000,"output_tokens":2000}}}`,
+			expectedInput:  5000,
+			expectedOutput: 2000,
+		},
+		{
+			name:              "empty transcript",
+			transcriptContent: "",
+			expectedInput:     0,
+			expectedOutput:    0,
+		},
+		{
+			name: "malformed json lines are ignored",
+			transcriptContent: `not json
+{"type":"assistant","message":{"role":"assistant","usage":{"input_tokens":3000,"output_tokens":1000}}}
+also not json`,
+			expectedInput:  3000,
+			expectedOutput: 1000,
+		},
+		{
+			name:           "file not found returns empty context",
+			fileError:      os.ErrNotExist,
+			expectedInput:  0,
+			expectedOutput: 0,
+			expectError:    false,
+		},
+		{
+			name:        "other file error",
+			fileError:   errors.New("permission denied"),
+			expectError: true,
+		},
+		{
+			name:              "user messages are ignored",
+			transcriptContent: `{"type":"user","message":{"role":"user","usage":{"input_tokens":1000,"output_tokens":500}}}`,
+			expectedInput:     0,
+			expectedOutput:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &Parser{
+				GetTranscriptFile: func(path string) (*os.File, error) {
+					if tt.fileError != nil {
+						return nil, tt.fileError
+					}
+
+					tempFile, err := os.CreateTemp("", "test-transcript-*.jsonl")
+					if err != nil {
+						t.Fatal(err)
+					}
+					t.Cleanup(func() { os.Remove(tempFile.Name()) })
+
+					_, err = tempFile.WriteString(tt.transcriptContent)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					_, err = tempFile.Seek(0, 0)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					return tempFile, nil
+				},
+			}
+
+			context, err := parser.ParseContextFromTranscript("/test/path")
+
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedInput, context.InputTokenCount)
+			assert.Equal(t, tt.expectedOutput, context.OutputTokenCount)
+			assert.Equal(t, 200000, context.MaxTokenCount)
+			if tt.expectedNotes != "" {
+				assert.Contains(t, context.Notes, tt.expectedNotes)
+			}
+		})
+	}
+}
+
+func TestParserWithRealFile(t *testing.T) {
+	tempContent := `{"type":"assistant","message":{"role":"assistant","usage":{"input_tokens":10000,"output_tokens":5000,"cache_creation_input_tokens":2000,"cache_read_input_tokens":1000}}}`
+
+	tempFile, err := os.CreateTemp("", "transcript-*.jsonl")
+	require.NoError(t, err)
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.WriteString(tempContent)
+	require.NoError(t, err)
+	tempFile.Close()
+
+	parser := NewParser()
+	context, err := parser.ParseContextFromTranscript(tempFile.Name())
+
+	require.NoError(t, err)
+	assert.Equal(t, 13000, context.InputTokenCount)
+	assert.Equal(t, 5000, context.OutputTokenCount)
+}
+
+func TestParserMaxTokenCount(t *testing.T) {
+	parser := NewParser()
+	parser.MaxTokenCount = GetModelMaxTokens("claude-sonnet-4-5[1m]")
+
+	context, err := parser.ParseContextFromTranscript("/nonexistent/transcript.jsonl")
+	require.NoError(t, err)
+	assert.Equal(t, 1000000, context.MaxTokenCount)
+}
+
+func TestParserIncremental(t *testing.T) {
+	const (
+		userLine  = `{"type":"user","message":{"role":"user"}}` + "\n"
+		firstLine = `{"type":"assistant","message":{"role":"assistant","usage":{"input_tokens":1000,"output_tokens":100}}}` + "\n"
+		nextLine  = `{"type":"assistant","message":{"role":"assistant","usage":{"input_tokens":2000,"output_tokens":200}}}` + "\n"
+	)
+
+	newParser := func(cache *filecache.Cache) *Parser {
+		parser := NewParser()
+		parser.Cache = cache
+		parser.SessionID = "session-1"
+		return parser
+	}
+
+	appendLines := func(t *testing.T, path string, lines ...string) {
+		t.Helper()
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+		require.NoError(t, err)
+		defer f.Close()
+		for _, line := range lines {
+			_, err = f.WriteString(line)
+			require.NoError(t, err)
+		}
+	}
+
+	cacheKey := func(path string) string {
+		return "transcript:session-1:" + path
+	}
+
+	t.Run("only appended lines are parsed", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "transcript.jsonl")
+		require.NoError(t, os.WriteFile(path, []byte(userLine+firstLine), 0644))
+		cache := &filecache.Cache{Dir: t.TempDir()}
+
+		context, err := newParser(cache).ParseContextFromTranscript(path)
+		require.NoError(t, err)
+		assert.Equal(t, 1000, context.InputTokenCount)
+
+		var state transcriptState
+		_, found := cache.Load(cacheKey(path), &state)
+		require.True(t, found)
+		assert.Equal(t, int64(len(userLine+firstLine)), state.Offset)
+
+		// Tamper with the cached result: if the parser rescanned from the
+		// start it would overwrite this with the real usage.
+		state.Latest = &Usage{InputTokens: 42}
+		require.NoError(t, cache.Store(cacheKey(path), state))
+		appendLines(t, path, userLine)
+
+		context, err = newParser(cache).ParseContextFromTranscript(path)
+		require.NoError(t, err)
+		assert.Equal(t, 42, context.InputTokenCount)
+
+		appendLines(t, path, nextLine)
+		context, err = newParser(cache).ParseContextFromTranscript(path)
+		require.NoError(t, err)
+		assert.Equal(t, 2000, context.InputTokenCount)
+		assert.Equal(t, 200, context.OutputTokenCount)
+	})
+
+	t.Run("partial trailing line is re-read", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "transcript.jsonl")
+		require.NoError(t, os.WriteFile(path, []byte(firstLine+nextLine[:20]), 0644))
+		cache := &filecache.Cache{Dir: t.TempDir()}
+
+		context, err := newParser(cache).ParseContextFromTranscript(path)
+		require.NoError(t, err)
+		assert.Equal(t, 1000, context.InputTokenCount)
+
+		appendLines(t, path, nextLine[20:])
+		context, err = newParser(cache).ParseContextFromTranscript(path)
+		require.NoError(t, err)
+		assert.Equal(t, 2000, context.InputTokenCount)
+	})
+
+	t.Run("truncated file is rescanned", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "transcript.jsonl")
+		require.NoError(t, os.WriteFile(path, []byte(firstLine+nextLine), 0644))
+		cache := &filecache.Cache{Dir: t.TempDir()}
+
+		_, err := newParser(cache).ParseContextFromTranscript(path)
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(path, []byte(firstLine), 0644))
+		context, err := newParser(cache).ParseContextFromTranscript(path)
+		require.NoError(t, err)
+		assert.Equal(t, 1000, context.InputTokenCount)
+	})
+
+	t.Run("replaced file is rescanned", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "transcript.jsonl")
+		require.NoError(t, os.WriteFile(path, []byte(firstLine), 0644))
+		cache := &filecache.Cache{Dir: t.TempDir()}
+
+		_, err := newParser(cache).ParseContextFromTranscript(path)
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(path, []byte(nextLine+userLine+userLine), 0644))
+		context, err := newParser(cache).ParseContextFromTranscript(path)
+		require.NoError(t, err)
+		assert.Equal(t, 2000, context.InputTokenCount)
+	})
+}
+
+func TestReverseLineReader(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		start    int64
+		expected []string
+		complete []bool
+	}{
+		{
+			name:     "newline terminated",
+			content:  "one\ntwo\nthree\n",
+			expected: []string{"three", "two", "one"},
+			complete: []bool{true, true, true},
+		},
+		{
+			name:     "partial last line",
+			content:  "one\ntwo\nthr",
+			expected: []string{"thr", "two", "one"},
+			complete: []bool{false, true, true},
+		},
+		{
+			name:     "empty lines",
+			content:  "one\n\ntwo\n",
+			expected: []string{"two", "", "one"},
+			complete: []bool{true, true, true},
+		},
+		{
+			name:     "starts at offset",
+			content:  "one\ntwo\nthree\n",
+			start:    4,
+			expected: []string{"three", "two"},
+			complete: []bool{true, true},
+		},
+		{
+			name:    "empty range",
+			content: "one\n",
+			start:   4,
+		},
+		{
+			name:     "lines longer than a chunk",
+			content:  strings.Repeat("a", reverseChunkSize*3) + "\n" + strings.Repeat("b", reverseChunkSize+1) + "\nc\n",
+			expected: []string{"c", strings.Repeat("b", reverseChunkSize+1), strings.Repeat("a", reverseChunkSize*3)},
+			complete: []bool{true, true, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "lines.txt")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0644))
+			file, err := os.Open(path)
+			require.NoError(t, err)
+			defer file.Close()
+
+			reader := newReverseLineReader(file, tt.start, int64(len(tt.content)))
+			var lines []string
+			var complete []bool
+			for {
+				line, ok, err := reader.Next()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				lines = append(lines, string(line))
+				complete = append(complete, ok)
+			}
+
+			assert.Equal(t, tt.expected, lines)
+			assert.Equal(t, tt.complete, complete)
+		})
+	}
+}
+
+func TestParserStopsAtLatestAssistant(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	content := "this line is never read because it is not json\n" +
+		`{"type":"assistant","message":{"role":"assistant","usage":{"input_tokens":1000,"output_tokens":100}}}` + "\n" +
+		`{"type":"user","message":{"role":"user"}}` + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	context, err := NewParser().ParseContextFromTranscript(path)
+	require.NoError(t, err)
+	assert.Equal(t, 1000, context.InputTokenCount)
+	assert.Equal(t, 100, context.OutputTokenCount)
+}
+
+// writeBenchmarkTranscript writes a transcript of roughly size bytes made of
+// alternating user and assistant entries padded with message content.
+func writeBenchmarkTranscript(b *testing.B, size int) string {
+	b.Helper()
+	path := filepath.Join(b.TempDir(), "transcript.jsonl")
+	file, err := os.Create(path)
+	require.NoError(b, err)
+	defer file.Close()
+
+	padding := strings.Repeat("x", 2000)
+	writer := bufio.NewWriter(file)
+	for written, i := 0, 0; written < size; i++ {
+		n, err := fmt.Fprintf(writer, `{"type":"user","message":{"role":"user","content":"%s"}}`+"\n"+
+			`{"type":"assistant","message":{"role":"assistant","content":"%s","usage":{"input_tokens":%d,"output_tokens":100}}}`+"\n",
+			padding, padding, i)
+		require.NoError(b, err)
+		written += n
+	}
+	require.NoError(b, writer.Flush())
+	return path
+}
+
+// forwardScanLatestAssistant is the original forward scanning implementation,
+// kept as the baseline for the reverse reader benchmarks.
+func forwardScanLatestAssistant(path string) (*Usage, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	var latest *Usage
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if entry.Type == "assistant" && entry.Message.Role == "assistant" {
+			usage := entry.Message.Usage
+			latest = &usage
+		}
+	}
+	return latest, scanner.Err()
+}
+
+func BenchmarkTranscriptLatestAssistant(b *testing.B) {
+	for _, size := range []int{100 * 1024, 1024 * 1024, 10 * 1024 * 1024} {
+		path := writeBenchmarkTranscript(b, size)
+
+		b.Run(fmt.Sprintf("forward/%dKB", size/1024), func(b *testing.B) {
+			for b.Loop() {
+				if _, err := forwardScanLatestAssistant(path); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("reverse/%dKB", size/1024), func(b *testing.B) {
+			parser := NewParser()
+			for b.Loop() {
+				if _, err := parser.ParseContextFromTranscript(path); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestParserParseCostFromTranscript(t *testing.T) {
+	const (
+		sonnetLine = `{"type":"assistant","requestId":"req_1","message":{"id":"msg_1","model":"claude-sonnet-4-20250514","role":"assistant",` +
+			`"usage":{"input_tokens":1000000,"output_tokens":100000,"cache_creation_input_tokens":200000,"cache_read_input_tokens":2000000}}}` + "\n"
+		opusLine = `{"type":"assistant","requestId":"req_2","message":{"id":"msg_2","model":"claude-opus-4-1-20250805","role":"assistant",` +
+			`"usage":{"input_tokens":0,"output_tokens":100000}}}` + "\n"
+		unknownLine = `{"type":"assistant","message":{"model":"mystery","role":"assistant","usage":{"input_tokens":500}}}` + "\n"
+		userLine    = `{"type":"user","message":{"role":"user","usage":{"input_tokens":999999}}}` + "\n"
+	)
+
+	t.Run("prices each response once", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "transcript.jsonl")
+		// The same response is logged once per content block.
+		require.NoError(t, os.WriteFile(path, []byte(userLine+sonnetLine+sonnetLine+opusLine+unknownLine), 0644))
+
+		breakdown, err := NewParser().ParseCostFromTranscript(path)
+		require.NoError(t, err)
+		assert.InDelta(t, 3.0, breakdown.Input, 1e-9)
+		assert.InDelta(t, 1.5+7.5, breakdown.Output, 1e-9)
+		assert.InDelta(t, 0.75, breakdown.CacheWrite, 1e-9)
+		assert.InDelta(t, 0.6, breakdown.CacheRead, 1e-9)
+		assert.Equal(t, 500, breakdown.UnpricedTokens)
+	})
+
+	t.Run("accumulates incrementally", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "transcript.jsonl")
+		require.NoError(t, os.WriteFile(path, []byte(sonnetLine+opusLine[:30]), 0644))
+
+		parser := NewParser()
+		parser.Cache = &filecache.Cache{Dir: t.TempDir()}
+
+		breakdown, err := parser.ParseCostFromTranscript(path)
+		require.NoError(t, err)
+		assert.InDelta(t, 5.85, breakdown.Total(), 1e-9, "partial line is not priced yet")
+
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+		require.NoError(t, err)
+		_, err = f.WriteString(opusLine[30:] + sonnetLine)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		breakdown, err = parser.ParseCostFromTranscript(path)
+		require.NoError(t, err)
+		assert.InDelta(t, 5.85+7.5+5.85, breakdown.Total(), 1e-9)
+	})
+
+	t.Run("missing transcript", func(t *testing.T) {
+		breakdown, err := NewParser().ParseCostFromTranscript(filepath.Join(t.TempDir(), "missing.jsonl"))
+		require.NoError(t, err)
+		assert.Zero(t, breakdown.Total())
+	})
+}
+
+func TestParserReadAssistantEntriesFrom(t *testing.T) {
+	const (
+		firstLine  = `{"type":"assistant","message":{"id":"msg_1","role":"assistant","usage":{"input_tokens":100}}}` + "\n"
+		secondLine = `{"type":"assistant","message":{"id":"msg_2","role":"assistant","usage":{"input_tokens":200}}}` + "\n"
+		userLine   = `{"type":"user","message":{"role":"user"}}` + "\n"
+	)
+	ids := func(entries []Entry) []string {
+		var ids []string
+		for _, entry := range entries {
+			ids = append(ids, entry.Message.ID)
+		}
+		return ids
+	}
+
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(userLine+firstLine+secondLine[:20]), 0644))
+
+	parser := NewParser()
+	var cursor Cursor
+	entries, reset, err := parser.ReadAssistantEntriesFrom(path, &cursor)
+	require.NoError(t, err)
+	assert.False(t, reset)
+	assert.Equal(t, []string{"msg_1"}, ids(entries), "partial line is left for later")
+	assert.Equal(t, int64(len(userLine+firstLine)), cursor.Offset)
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString(secondLine[20:])
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	entries, reset, err = parser.ReadAssistantEntriesFrom(path, &cursor)
+	require.NoError(t, err)
+	assert.False(t, reset)
+	assert.Equal(t, []string{"msg_2"}, ids(entries))
+
+	entries, _, err = parser.ReadAssistantEntriesFrom(path, &cursor)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	// A replaced transcript is read again from the start.
+	require.NoError(t, os.WriteFile(path, []byte(secondLine), 0644))
+	entries, reset, err = parser.ReadAssistantEntriesFrom(path, &cursor)
+	require.NoError(t, err)
+	assert.True(t, reset)
+	assert.Equal(t, []string{"msg_2"}, ids(entries))
+}
+
+func TestEntryStructure(t *testing.T) {
+	jsonData := `{
+		"parentUuid": "parent-123",
+		"uuid": "uuid-456",
+		"type": "assistant",
+		"message": {
+			"role": "assistant",
+			"usage": {
+				"input_tokens": 100,
+				"output_tokens": 50,
+				"cache_creation_input_tokens": 20,
+				"cache_read_input_tokens": 10
+			}
+		}
+	}`
+
+	var entry Entry
+	err := json.Unmarshal([]byte(jsonData), &entry)
+
+	require.NoError(t, err)
+	assert.Equal(t, "parent-123", entry.ParentUUID)
+	assert.Equal(t, "uuid-456", entry.UUID)
+	assert.Equal(t, "assistant", entry.Type)
+	assert.Equal(t, "assistant", entry.Message.Role)
+	assert.Equal(t, 100, entry.Message.Usage.InputTokens)
+	assert.Equal(t, 50, entry.Message.Usage.OutputTokens)
+	assert.Equal(t, 20, entry.Message.Usage.CacheCreationInputTokens)
+	assert.Equal(t, 10, entry.Message.Usage.CacheReadInputTokens)
+}